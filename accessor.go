@@ -32,6 +32,19 @@ const (
 	// KeyedNameToken marks a name token with a key suffix.
 	// Slice, Array or a Map field name with an index or key specifier.
 	KeyedNameToken
+	// WildcardToken marks a wildcard token.
+	// Any struct field, Slice or Array index or Map key, specified as "*" or
+	// "[*]".
+	WildcardToken
+	// RecursiveToken marks a recursive descent token.
+	// Any number of struct fields, Slice or Array indexes or Map keys,
+	// including none, specified as "**".
+	RecursiveToken
+	// RangeToken marks an index range token.
+	// Slice or Array indexes from a low index up to but not including a high
+	// index, specified as "[low:high]", where either index may be omitted or
+	// negative to count from the end.
+	RangeToken
 )
 
 // String implements stringer on Token.
@@ -47,6 +60,12 @@ func (t Token) String() (s string) {
 		s = "KeyToken"
 	case KeyedNameToken:
 		s = "KeyedNameToken"
+	case WildcardToken:
+		s = "WildcardToken"
+	case RecursiveToken:
+		s = "RecursiveToken"
+	case RangeToken:
+		s = "RangeToken"
 	}
 	return
 }
@@ -64,12 +83,134 @@ type Path struct {
 	path    string
 	current int
 	length  int
+	err     error
+}
+
+// Err returns a *PathSyntaxError describing why Next last returned an
+// InvalidToken or nil if it did not. If Next recovered from a panic the
+// error does not describe a syntax error and wraps an error describing the
+// panic instead.
+func (p *Path) Err() error {
+	return p.err
+}
+
+// fail records a syntax error at offset i and returns InvalidToken.
+func (p *Path) fail(i int, expected string) (string, Token) {
+	p.err = newPathSyntaxError(p.path, i, expected)
+	return "", InvalidToken
+}
+
+// Element is a single element of a path.
+type Element struct {
+	// Token is the element token; NameToken, KeyToken, WildcardToken,
+	// RecursiveToken or RangeToken.
+	Token Token
+	// Name is the struct field name if Token is NameToken.
+	Name string
+	// Key is the unquoted index or key if Token is KeyToken or the range if
+	// Token is RangeToken.
+	Key string
+}
+
+// Elements returns all elements of the path regardless of the position of
+// Next. A KeyedNameToken produces a NameToken element followed by an element
+// for its key. Returns an error if the path is invalid.
+func (p *Path) Elements() (elements []Element, err error) {
+	defer recoverError(&err)
+	var segs []segment
+	if segs, err = segments(p.path); err != nil {
+		return nil, err
+	}
+	var result = make([]Element, 0, len(segs))
+	for _, seg := range segs {
+		result = append(result, Element{Token: seg.token, Name: seg.name, Key: seg.key})
+	}
+	return result, nil
+}
+
+// String returns the path in canonical form, or as it was specified if it
+// is invalid. Parsing the canonical form results in the same elements and
+// the same canonical form.
+func (p *Path) String() string {
+	var segs, err = segments(p.path)
+	if err != nil {
+		return p.path
+	}
+	return segmentsToPath(segs)
 }
 
 // Next returns next token in Path.
 // Returns an empty string and NoToken if there are no tokens left.
-// Returns an empty string and InvalidToken if the token is invalid.
+// Returns an empty string and InvalidToken if the token is invalid, in which
+// case Err returns the syntax error.
+//
+// Wildcard, recursive descent and range elements are returned as
+// WildcardToken, RecursiveToken and RangeToken unless they are a key suffix
+// of a KeyedNameToken in which case ParseElement returns them as the key
+// and KeyToken classifies them.
 func (p *Path) Next() (element string, token Token) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.err = fmt.Errorf("%w: %v", errPanic, r)
+			element, token = "", InvalidToken
+		}
+	}()
+	element, token = p.next()
+	switch token {
+	case NameToken:
+		token = nameToken(element)
+	case KeyToken:
+		token = KeyTokenOf(element[1 : len(element)-1])
+	}
+	return
+}
+
+// nameToken returns the token of a name specified as name.
+// Returns WildcardToken for "*", RecursiveToken for "**" and NameToken
+// otherwise.
+func nameToken(name string) Token {
+	switch name {
+	case "*":
+		return WildcardToken
+	case "**":
+		return RecursiveToken
+	}
+	return NameToken
+}
+
+// KeyTokenOf returns the token of an unquoted key specified as key.
+// Returns WildcardToken for "*", RangeToken for a range of indexes and
+// KeyToken otherwise.
+func KeyTokenOf(key string) Token {
+	if key == "*" {
+		return WildcardToken
+	}
+	var i = strings.IndexByte(key, ':')
+	if i < 0 || !isIndex(key[:i]) || !isIndex(key[i+1:]) {
+		return KeyToken
+	}
+	return RangeToken
+}
+
+// isIndex returns true if s is empty or consists of decimal digits only,
+// optionally prefixed with a minus sign.
+func isIndex(s string) bool {
+	if strings.HasPrefix(s, "-") {
+		if s = s[1:]; s == "" {
+			return false
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// next returns next token in Path without classifying wildcards.
+func (p *Path) next() (element string, token Token) {
+	p.err = nil
 	var i int
 	for i = p.current; i < p.length; i++ {
 		switch p.path[i] {
@@ -80,16 +221,26 @@ func (p *Path) Next() (element string, token Token) {
 			case NameToken:
 				token = KeyedNameToken
 			case KeyToken, KeyedNameToken:
-				return "", InvalidToken
+				return p.fail(i, "']'")
+			}
+		case '"':
+			switch token {
+			case KeyToken, KeyedNameToken:
+				var start = i
+				if i = closingQuote(p.path, i); i < 0 {
+					return p.fail(start, "closed quoted key")
+				}
+			case InvalidToken:
+				token = NameToken
 			}
 		case ']':
 			switch token {
 			case InvalidToken, NameToken:
-				return "", InvalidToken
+				return p.fail(i, "'[' before ']'")
 			}
 			element = p.path[p.current : i+1]
 			if !validElement(&element) {
-				return "", InvalidToken
+				return p.fail(i, "key")
 			}
 			p.current = i + 1
 			return
@@ -100,11 +251,11 @@ func (p *Path) Next() (element string, token Token) {
 				p.current = i + 1
 				continue
 			case KeyToken, KeyedNameToken:
-				return "", InvalidToken
+				return p.fail(i, "']'")
 			case NameToken:
 				element = p.path[p.current:i]
 				if !validElement(&element) {
-					return "", InvalidToken
+					return p.fail(i, "name")
 				}
 				p.current = i
 				return
@@ -122,11 +273,11 @@ func (p *Path) Next() (element string, token Token) {
 	case InvalidToken:
 		return "", NoToken
 	case KeyToken, KeyedNameToken:
-		return "", InvalidToken
+		return p.fail(i, "']'")
 	case NameToken:
 		element = p.path[p.current:i]
 		if !validElement(&element) {
-			return "", InvalidToken
+			return p.fail(i, "name")
 		}
 		p.current = i
 		return
@@ -134,6 +285,20 @@ func (p *Path) Next() (element string, token Token) {
 	return "", NoToken
 }
 
+// closingQuote returns the index of the double quote that closes the quoted
+// string starting with a double quote at index i in s or -1 if there is none.
+func closingQuote(s string, i int) int {
+	for i++; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
 // validElement returns if element is a valid element.
 // Element is valid if its length is not zero and if its key length is not zero,
 // if present.
@@ -152,10 +317,15 @@ func validElement(element *string) bool {
 // ParseElement parses element string into:
 // name and key if token is KeyedNameToken.
 // name if token is NameToken.
-// key if token is KeyToken.
+// key if token is KeyToken or RangeToken.
 // If element is an empty string or token is not one of above listed tokens
-// ParseElement returns empty name and key and ErrInvalidArgument.
+// or WildcardToken or RecursiveToken ParseElement returns empty name and key
+// and ErrInvalidArgument.
+//
+// Keys are unquoted except for KeyedNameToken whose key is returned verbatim
+// if KeyTokenOf classifies it as a WildcardToken or a RangeToken.
 func ParseElement(element string, token Token) (name, key string, err error) {
+	defer recoverError(&err)
 	if element == "" {
 		return "", "", ErrInvalidArgument
 	}
@@ -164,96 +334,312 @@ func ParseElement(element string, token Token) (name, key string, err error) {
 		err = ErrInvalidArgument
 	case NameToken:
 		return element, "", nil
+	case WildcardToken, RecursiveToken:
+		return "", "", nil
+	case RangeToken:
+		return "", strings.TrimPrefix(strings.TrimSuffix(element, "]"), "["), nil
 	case KeyToken:
-		if key, err = strconv.Unquote(
+		if key, err = unquoteKey(
 			strings.TrimPrefix(strings.TrimSuffix(element, "]"), "["),
 		); err != nil {
 			return "", "", err
 		}
 	case KeyedNameToken:
-		var a = strings.Split(element, "[")
-		if len(a) != 2 {
+		var i = strings.IndexByte(element, '[')
+		if i < 0 {
 			return "", "", ErrInvalidPath
 		}
-		name = a[0]
-		key = strings.TrimSuffix(a[1], "]")
+		name = element[:i]
+		if key = strings.TrimSuffix(element[i+1:], "]"); KeyTokenOf(key) != KeyToken {
+			return
+		}
+		if key, err = unquoteKey(key); err != nil {
+			return "", "", err
+		}
 	}
 	return
 }
 
+// unquoteKey unquotes key if it is quoted or returns it unmodified otherwise.
+func unquoteKey(key string) (string, error) {
+	if len(key) < 2 || key[0] != '"' {
+		return key, nil
+	}
+	return strconv.Unquote(key)
+}
+
+// segment is a single step of a path; a struct field name if token is
+// NameToken, an index or key into an Array, Slice or Map if token is
+// KeyToken, a range of indexes if token is RangeToken or a wildcard if token
+// is WildcardToken or RecursiveToken.
+type segment struct {
+	token Token
+	name  string
+	key   string
+
+	// Following fields are set when a segment is bound to a type by
+	// CompiledPath to skip resolving name and key by reflection.
+
+	// field is the index of the struct field specified by name.
+	field []int
+	// index is the Array or Slice index specified by key, valid if indexed.
+	index   int
+	indexed bool
+	// mapkey is the Map key specified by key, if valid.
+	mapkey reflect.Value
+}
+
+// segments splits path into segments. A KeyedNameToken element produces a
+// name segment, unless the name is empty, followed by a key segment.
+func segments(path string) ([]segment, error) {
+	if path == "" {
+		return nil, ErrInvalidPath
+	}
+	var result []segment
+	var element, name, key string
+	var parser = Parse(path)
+	var token Token
+	var err error
+	for {
+		switch element, token = parser.Next(); token {
+		case InvalidToken:
+			return nil, parser.Err()
+		case NoToken:
+			return result, nil
+		case NameToken:
+			result = append(result, segment{token: NameToken, name: element})
+		case WildcardToken, RecursiveToken:
+			result = append(result, segment{token: token})
+		case KeyToken, RangeToken:
+			if _, key, err = ParseElement(element, token); err != nil {
+				return nil, keySyntaxError(path, parser.current-len(element), element)
+			}
+			result = append(result, newSegment(token, "", key))
+		case KeyedNameToken:
+			if name, key, err = ParseElement(element, token); err != nil {
+				return nil, keySyntaxError(path, parser.current-len(element), element)
+			}
+			// A key after a separator, e.g. "A.[0]", has an empty name which
+			// addresses nothing and is dropped so that "A[0]" is canonical.
+			if name != "" {
+				result = append(result, newSegment(nameToken(name), name, ""))
+			}
+			result = append(result, newSegment(KeyTokenOf(element[len(name)+1:len(element)-1]), "", key))
+		}
+	}
+}
+
+// newSegment returns a new segment of token with name and key. Wildcard
+// and recursive segments carry neither so that they compare equal however
+// they were specified.
+func newSegment(token Token, name, key string) segment {
+	if token == WildcardToken || token == RecursiveToken {
+		return segment{token: token}
+	}
+	return segment{token: token, name: name, key: key}
+}
+
+// keySyntaxError returns a *PathSyntaxError for an element with an invalid
+// quoted key that starts at offset in path.
+func keySyntaxError(path string, offset int, element string) error {
+	if i := strings.IndexByte(element, '"'); i >= 0 {
+		offset += i
+	}
+	return newPathSyntaxError(path, offset, "valid quoted key")
+}
+
+// segmentsToPath returns the path that segs were parsed from in canonical
+// form.
+func segmentsToPath(segs []segment) string {
+	var path string
+	for _, seg := range segs {
+		switch seg.token {
+		case NameToken:
+			path = joinName(path, seg.name)
+		case KeyToken:
+			path = joinKey(path, quoteKey(seg.key))
+		case WildcardToken:
+			path = joinKey(path, "*")
+		case RecursiveToken:
+			path = joinName(path, "**")
+		case RangeToken:
+			path = joinKey(path, seg.key)
+		}
+	}
+	return path
+}
+
 // Find searches for a Go value in a compound Go value specified by root by
 // specified path and returns it as a reflect Value or an error.
 //
 // Simple go values as root are supported too but the path must be empty.
 //
-//
 // Path syntax is as follows:
 // Maps: Name[Key]
 // Slices and Arrays: Name[Index]
 // Struct fields: Name
-// Strings must be quoted when used as keys. Quotes must be escaped.
+// Keys may be quoted. Quoted keys follow Go string literal rules.
+// Negative indexes count from the end so that -1 is the last element.
 // Elements in hierarchy are dot separated.
+// Pointers and interfaces along the path are dereferenced.
 //
 // For example:
 //
 // Access second element in root struct value field named "Slice":
-//  Slice[1]
 //
-// Access struct field named "Age" in a map[string]struct entry "Example":
-//  [Example].Age
+//	Slice[1]
 //
+// Access struct field named "Age" in a map[string]struct entry "Example":
 //
-func Find(path string, root interface{}) (reflect.Value, error) {
-	if path == "" {
-		return reflect.Value{}, ErrInvalidPath
-	}
+//	[Example].Age
+func Find(path string, root interface{}) (v reflect.Value, err error) {
+	defer recoverError(&err)
 	if root == nil {
 		return reflect.Value{}, ErrInvalidArgument
 	}
-	var current = reflect.Indirect(reflect.ValueOf(root))
-	var element, name, key string
-	var parser = Parse(path)
-	var token Token
+	var segs []segment
+	if segs, err = segments(path); err != nil {
+		return reflect.Value{}, err
+	}
+	return find(reflect.ValueOf(root), segs)
+}
+
+// errWildcard is returned when a path that must specify a single value
+// contains a wildcard.
+var errWildcard = fmt.Errorf("%w: wildcard not allowed", ErrInvalidPath)
+
+// errNilValue is returned when a path descends into a nil value.
+var errNilValue = fmt.Errorf("%w: nil value", ErrInvalidPath)
+
+// find resolves segs starting from value v. Resolution failures are
+// returned as *ResolveError.
+func find(v reflect.Value, segs []segment) (reflect.Value, error) {
+	var next reflect.Value
 	var err error
-loop:
-	for {
-		switch element, token = parser.Next(); token {
-		case InvalidToken:
-			return reflect.Value{}, ErrInvalidPath
-		case NoToken:
-			break loop
-		case NameToken:
-			if current.Kind() != reflect.Struct {
-				return reflect.Value{}, ErrInvalidPath
-			}
-			current = current.FieldByName(element)
-		case KeyToken:
-			if _, key, err = ParseElement(element, token); err != nil {
-				return reflect.Value{}, err
-			}
-			return valueByKey(current, key)
-		case KeyedNameToken:
-			if current.Kind() != reflect.Struct {
-				return reflect.Value{}, ErrInvalidPath
-			}
-			if name, key, err = ParseElement(element, token); err != nil {
-				return reflect.Value{}, err
-			}
-			if current = current.FieldByName(name); !current.IsValid() {
-				return reflect.Value{}, ErrInvalidPath
-			}
-			if current, err = valueByKey(current, key); err != nil {
-				return reflect.Value{}, err
+	for i, seg := range segs {
+		if seg.token != NameToken && seg.token != KeyToken {
+			return reflect.Value{}, errWildcard
+		}
+		if next = indirect(v); !next.IsValid() {
+			return reflect.Value{}, newResolveError(segs, i, v, errNilValue)
+		}
+		if seg.token == NameToken {
+			next, err = fieldBySegment(next, seg, false)
+		} else {
+			next, err = elemBySegment(next, seg)
+		}
+		if err != nil {
+			return reflect.Value{}, newResolveError(segs, i, indirect(v), err)
+		}
+		v = next
+	}
+	return v, nil
+}
+
+// fieldBySegment returns the field of struct v specified by seg. If alloc is
+// true nil embedded struct pointers on the way to a promoted field are
+// allocated, otherwise an error is returned for them.
+func fieldBySegment(v reflect.Value, seg segment, alloc bool) (reflect.Value, error) {
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("%w: not a struct", ErrInvalidPath)
+	}
+	var index = seg.field
+	if index == nil {
+		var field, err = exportedField(v.Type(), seg.name)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		index = field.Index
+	}
+	return fieldByIndex(v, index, alloc)
+}
+
+// exportedField returns the field of struct type t named name, including
+// fields promoted from embedded structs. Returns an error wrapping
+// ErrInvalidPath if there is no such field or ErrUnexportedField if the
+// field is unexported.
+func exportedField(t reflect.Type, name string) (reflect.StructField, error) {
+	var field, ok = t.FieldByName(name)
+	if !ok {
+		return field, fmt.Errorf("%w: field not found: %s", ErrInvalidPath, name)
+	}
+	if field.PkgPath != "" {
+		return field, fmt.Errorf("%w: %s", ErrUnexportedField, name)
+	}
+	return field, nil
+}
+
+// fieldByIndex returns the nested field of struct v specified by index. If
+// alloc is true nil embedded struct pointers on the way are allocated,
+// otherwise an error is returned for them.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Value{}, errNilValue
+				}
+				if !v.CanSet() {
+					if v.CanAddr() {
+						// An embedded pointer to an unexported type.
+						return reflect.Value{}, ErrUnexportedField
+					}
+					return reflect.Value{}, ErrUnaddressableValue
+				}
+				v.Set(reflect.New(v.Type().Elem()))
 			}
+			v = v.Elem()
 		}
+		v = v.Field(x)
 	}
-	return current, nil
+	return v, nil
+}
+
+// elemBySegment is like valueByKey but uses the index or Map key bound to
+// seg if it has one.
+func elemBySegment(v reflect.Value, seg segment) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		if !seg.indexed {
+			break
+		}
+		var i = seg.index
+		if i < 0 {
+			i += v.Len()
+		}
+		if i < 0 || i >= v.Len() {
+			return reflect.Value{}, &IndexError{Index: seg.index, Length: v.Len()}
+		}
+		return v.Index(i), nil
+	case reflect.Map:
+		if !seg.mapkey.IsValid() {
+			break
+		}
+		if v = v.MapIndex(seg.mapkey); !v.IsValid() {
+			return reflect.Value{}, fmt.Errorf("%w: key not found: %s", ErrInvalidPath, seg.key)
+		}
+		return v, nil
+	}
+	return valueByKey(v, seg.key)
+}
+
+// indirect dereferences pointers and interfaces in v until a value of some
+// other kind is reached. Returns an invalid value if a nil is encountered.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
 }
 
 // valueByKey retrieves an Array or Slice element or a map key by specified key
 // from value which must be an Array, Slice or Map. Key must be convertible to
 // an integer index if value is an Array or Slice and must be in range and must
-// be convertible to key type of Map if value is a map.
+// be convertible to key type of Map if value is a map. Negative indexes count
+// from the end.
 // If an error occurs it is returned with an invalid/zero value.
 func valueByKey(value reflect.Value, key string) (reflect.Value, error) {
 	var i int
@@ -261,25 +647,275 @@ func valueByKey(value reflect.Value, key string) (reflect.Value, error) {
 	var mapkey reflect.Value
 	switch value.Kind() {
 	case reflect.Array, reflect.Slice:
-		if i, err = strconv.Atoi(key); err != nil {
-			return reflect.Value{}, fmt.Errorf("%w: element to index: %v", ErrInvalidPath, err)
-		}
-		if i >= value.Len() {
-			return reflect.Value{}, errors.New("index out of range")
+		if i, err = parseIndex(key, value.Len(), false); err != nil {
+			return reflect.Value{}, err
 		}
 		value = value.Index(i)
 	case reflect.Map:
-		mapkey = reflect.Indirect(reflect.New(value.Type().Key()))
-		if err = StringToValue(key, mapkey); err != nil {
-			return reflect.Value{}, fmt.Errorf("%w: key to value: %v", ErrInvalidPath, err)
+		if mapkey, err = mapKey(value, key); err != nil {
+			return reflect.Value{}, err
+		}
+		if value = value.MapIndex(mapkey); !value.IsValid() {
+			return reflect.Value{}, fmt.Errorf("%w: key not found: %s", ErrInvalidPath, key)
 		}
-		value = value.MapIndex(mapkey)
 	default:
-		return reflect.Value{}, ErrInvalidPath
+		return reflect.Value{}, fmt.Errorf("%w: not an array, slice or map", ErrInvalidPath)
 	}
 	return value, nil
 }
 
+// mapKey converts key to a value of key type of Map m.
+func mapKey(m reflect.Value, key string) (reflect.Value, error) {
+	var mapkey = reflect.New(m.Type().Key()).Elem()
+	if err := StringToValue(key, mapkey); err != nil {
+		return reflect.Value{}, fmt.Errorf("%w: key to value: %v", ErrInvalidPath, err)
+	}
+	return mapkey, nil
+}
+
+// parseIndex converts key to an index into a sequence of specified length.
+// Negative indexes count from the end so that -1 is the last element.
+// Resulting index must be in range [0, length) or, if end is true, in range
+// [0, length]. Returns an *IndexError if it is not.
+func parseIndex(key string, length int, end bool) (int, error) {
+	var i, err = strconv.Atoi(key)
+	if err != nil {
+		return 0, fmt.Errorf("%w: element to index: %v", ErrInvalidPath, err)
+	}
+	var index = i
+	if index < 0 {
+		index += length
+	}
+	if index < 0 || index > length || (index == length && !end) {
+		return 0, &IndexError{Index: i, Length: length}
+	}
+	return index, nil
+}
+
+// modify resolves segs starting from value v and calls f with the value
+// found. Pointers along the path, including the one possibly found at the
+// end, are dereferenced and allocated if nil. Map elements and values of
+// non-nil interfaces along the path are copied to addressable values and
+// stored back into their maps, which are allocated if nil, and interfaces
+// after f returns without an error. A missing map element is created from a
+// zero value. If grow is true Slices are grown to accommodate indexes out of
+// their range. Resolution failures are returned as *ResolveError.
+//
+// If resolution or f fails, pointers allocated and Slices grown along the
+// path are restored and no map elements are stored so that v is left as it
+// was, except for changes f made before failing.
+func modify(v reflect.Value, segs []segment, grow bool, f func(reflect.Value) error) error {
+	var j journal
+	var err = modifyAt(v, segs, 0, grow, &j, f)
+	if err != nil {
+		j.undo(0)
+	}
+	return err
+}
+
+// modifyAt implements modify for segs starting at index i, recording the
+// changes it makes to values along the path in j.
+func modifyAt(v reflect.Value, segs []segment, i int, grow bool, j *journal, f func(reflect.Value) error) (err error) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			if !v.CanSet() {
+				return ErrUnaddressableValue
+			}
+			j.set(v, reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		if !v.CanSet() {
+			return ErrUnaddressableValue
+		}
+		var elem = reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		if err = modifyAt(elem, segs, i, grow, j, f); err != nil {
+			return err
+		}
+		j.set(v, elem)
+		return nil
+	}
+	if i == len(segs) {
+		return f(v)
+	}
+	var seg = segs[i]
+	if seg.token != NameToken && seg.token != KeyToken {
+		return errWildcard
+	}
+	if seg.token == NameToken {
+		var field reflect.Value
+		if field, err = fieldBySegment(v, seg, false); errors.Is(err, errNilValue) {
+			// Pointers below the outermost nil one are allocated with it.
+			if ptr := nilEmbedded(v, seg); ptr.CanSet() {
+				j.set(ptr, reflect.New(ptr.Type().Elem()))
+			}
+			field, err = fieldBySegment(v, seg, true)
+		}
+		if err != nil {
+			if errors.Is(err, ErrInvalidPath) || errors.Is(err, ErrUnexportedField) {
+				err = newResolveError(segs, i, v, err)
+			}
+			return err
+		}
+		return modifyAt(field, segs, i+1, grow, j, f)
+	}
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		if grow && v.Kind() == reflect.Slice {
+			if err = growSlice(v, seg.key, j); err != nil {
+				return err
+			}
+		}
+		var elem reflect.Value
+		if elem, err = elemBySegment(v, seg); err != nil {
+			return newResolveError(segs, i, v, err)
+		}
+		return modifyAt(elem, segs, i+1, grow, j, f)
+	case reflect.Map:
+		var key = seg.mapkey
+		if !key.IsValid() {
+			if key, err = mapKey(v, seg.key); err != nil {
+				return newResolveError(segs, i, v, err)
+			}
+		}
+		if v.IsNil() && !v.CanSet() {
+			return ErrUnaddressableValue
+		}
+		var elem = reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+		if err = modifyAt(elem, segs, i+1, grow, j, f); err != nil {
+			return err
+		}
+		if v.IsNil() {
+			j.set(v, reflect.MakeMap(v.Type()))
+		}
+		j.setMapIndex(v, key, elem)
+		return nil
+	}
+	return newResolveError(segs, i, v, fmt.Errorf("%w: not an array, slice or map", ErrInvalidPath))
+}
+
+// journal records changes made to values so that they can be undone. Its
+// methods are safe to call on a nil *journal, which records nothing.
+type journal []undoStep
+
+// undoStep is a change recorded in a journal.
+type undoStep struct {
+	v   reflect.Value // settable value or Map changed
+	key reflect.Value // key of the Map element changed, if v is a Map
+	old reflect.Value // value before the change, invalid if there was no Map element
+}
+
+// set sets settable v to x and records the change.
+func (j *journal) set(v, x reflect.Value) {
+	if j != nil {
+		var old = reflect.New(v.Type()).Elem()
+		old.Set(v)
+		*j = append(*j, undoStep{v: v, old: old})
+	}
+	v.Set(x)
+}
+
+// setMapIndex sets the element of non-nil Map m at key to elem, or deletes
+// it if elem is invalid, and records the change.
+func (j *journal) setMapIndex(m, key, elem reflect.Value) {
+	if j != nil {
+		*j = append(*j, undoStep{v: m, key: key, old: m.MapIndex(key)})
+	}
+	m.SetMapIndex(key, elem)
+}
+
+// undo undoes the changes recorded in j after the first n in reverse order
+// and removes them from j.
+func (j *journal) undo(n int) {
+	if j == nil {
+		return
+	}
+	for i := len(*j) - 1; i >= n; i-- {
+		if step := (*j)[i]; step.key.IsValid() {
+			step.v.SetMapIndex(step.key, step.old)
+		} else {
+			step.v.Set(step.old)
+		}
+	}
+	*j = (*j)[:n]
+}
+
+// nilEmbedded returns the outermost nil embedded struct pointer of struct v
+// on the way to the promoted field specified by seg or an invalid value if
+// there is none.
+func nilEmbedded(v reflect.Value, seg segment) reflect.Value {
+	var index = seg.field
+	if index == nil {
+		var field, err = exportedField(v.Type(), seg.name)
+		if err != nil {
+			return reflect.Value{}
+		}
+		index = field.Index
+	}
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return v
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return reflect.Value{}
+}
+
+// growSlice grows Slice v with zero values so that index specified by key is
+// in range and records the change in j. Keys that are not valid indexes are
+// ignored.
+func growSlice(v reflect.Value, key string, j *journal) error {
+	var i, err = strconv.Atoi(key)
+	if err != nil || i < v.Len() {
+		return nil
+	}
+	if !v.CanSet() {
+		return ErrUnaddressableValue
+	}
+	j.set(v, reflect.AppendSlice(v, reflect.MakeSlice(v.Type(), i+1-v.Len(), i+1-v.Len())))
+	return nil
+}
+
+// modifyPath is like modify but takes a root interface and a path string.
+func modifyPath(path string, root interface{}, f func(reflect.Value) error) error {
+	if root == nil {
+		return ErrInvalidArgument
+	}
+	var segs, err = segments(path)
+	if err != nil {
+		return err
+	}
+	return modify(reflect.ValueOf(root), segs, false, f)
+}
+
+// modifyContainer splits path into a path to a container and the key of its
+// element specified by the last path element, which must be a key, then
+// calls f with the found container and the key.
+func modifyContainer(path string, root interface{}, f func(container reflect.Value, key string) error) error {
+	if root == nil {
+		return ErrInvalidArgument
+	}
+	var segs, err = segments(path)
+	if err != nil {
+		return err
+	}
+	var last = segs[len(segs)-1]
+	if last.token != KeyToken {
+		return ErrInvalidPath
+	}
+	return modify(reflect.ValueOf(root), segs[:len(segs)-1], false, func(v reflect.Value) error {
+		return f(v, last.key)
+	})
+}
+
 // MustFind is like Find but panics on error.
 func MustFind(path string, root interface{}) (v reflect.Value) {
 	var err error
@@ -291,9 +927,9 @@ func MustFind(path string, root interface{}) (v reflect.Value) {
 
 // Get retrieves a Go value from a Go compound value by path as an interface or
 // returns an error.
-func Get(path string, root interface{}) (interface{}, error) {
+func Get(path string, root interface{}) (value interface{}, err error) {
+	defer recoverError(&err)
 	var val reflect.Value
-	var err error
 	if val, err = Find(path, root); err != nil {
 		return nil, err
 	}
@@ -310,23 +946,251 @@ func MustGet(path string, root interface{}) interface{} {
 	return val.Interface()
 }
 
-func Set(path, value string, root interface{}) error {
-	var val reflect.Value
-	var err error
-	if val, err = Find(path, root); err != nil {
+// Set sets a Go value inside a Go compound value specified by root by path
+// to value converted by StringToValue. Root must be a pointer.
+//
+// Unlike Find, Set creates what is missing along the path: nil pointers,
+// including embedded struct pointers, are allocated, nil maps are made and
+// missing map elements are created from zero values. Map elements and values
+// of non-nil interfaces along the path are stored back into their maps and
+// interfaces. If Set fails root is left as it was.
+// []byte and [N]byte struct fields with a BytesTag are converted from its
+// encoding.
+//
+// The converted value is validated as Validate does, against the rules of
+// the struct field path ends with, if any, and the rules of fields nested in
+// it. It is not set if it fails validation.
+func Set(path, value string, root interface{}) (err error) {
+	defer recoverError(&err)
+	if root == nil {
+		return ErrInvalidArgument
+	}
+	var segs []segment
+	if segs, err = segments(path); err != nil {
 		return err
 	}
-	return StringToValue(value, reflect.Indirect(val))
+	return modify(reflect.ValueOf(root), segs, false, setString(value, segs, newSetTarget(segs, reflect.TypeOf(root))))
 }
 
 // MustSet is like Set but panics on error.
 func MustSet(path, value string, root interface{}) {
-	var val reflect.Value
-	var err error
-	if val, err = Find(path, root); err != nil {
+	if err := Set(path, value, root); err != nil {
 		panic(err)
 	}
-	if err = StringToValue(value, reflect.Indirect(val)); err != nil {
-		panic(err)
+}
+
+// Delete deletes an element from a Map or a Slice inside a Go compound value
+// specified by root by path which must end with a key or an index. Root
+// must be a pointer. Deleting a Slice element shifts the elements after it.
+//
+// For example:
+//
+//	Labels[env]
+func Delete(path string, root interface{}) (err error) {
+	defer recoverError(&err)
+	return modifyContainer(path, root, func(v reflect.Value, key string) error {
+		switch v.Kind() {
+		case reflect.Map:
+			var mapkey, err = mapKey(v, key)
+			if err != nil {
+				return err
+			}
+			if !v.MapIndex(mapkey).IsValid() {
+				return fmt.Errorf("%w: key not found: %s", ErrInvalidPath, key)
+			}
+			v.SetMapIndex(mapkey, reflect.Value{})
+			return nil
+		case reflect.Slice:
+			return removeAt(v, key, nil)
+		}
+		return ErrInvalidPath
+	})
+}
+
+// Append appends value converted by StringToValue to the Slice inside a Go
+// compound value specified by root by path. Root must be a pointer.
+//
+// For example:
+//
+//	Ports
+func Append(path, value string, root interface{}) (err error) {
+	defer recoverError(&err)
+	return modifyPath(path, root, func(v reflect.Value) error {
+		if v.Kind() != reflect.Slice {
+			return ErrInvalidPath
+		}
+		if !v.CanSet() {
+			return ErrUnaddressableValue
+		}
+		var elem = reflect.New(v.Type().Elem()).Elem()
+		if err := StringToValue(value, elem); err != nil {
+			return err
+		}
+		v.Set(reflect.Append(v, elem))
+		return nil
+	})
+}
+
+// Insert inserts value converted by StringToValue into a Slice inside a Go
+// compound value specified by root at the index specified by path which must
+// end with an index. Index may equal the Slice length in which case value is
+// appended. A negative index counts from the end so that -1 inserts before
+// the last element. Root must be a pointer.
+//
+// For example:
+//
+//	Hosts[0]
+func Insert(path, value string, root interface{}) (err error) {
+	defer recoverError(&err)
+	return modifyContainer(path, root, func(v reflect.Value, key string) error {
+		if v.Kind() != reflect.Slice {
+			return ErrInvalidPath
+		}
+		if !v.CanSet() {
+			return ErrUnaddressableValue
+		}
+		var i, err = parseIndex(key, v.Len(), true)
+		if err != nil {
+			return err
+		}
+		var elem = reflect.New(v.Type().Elem()).Elem()
+		if err = StringToValue(value, elem); err != nil {
+			return err
+		}
+		insertAt(v, i, elem, nil)
+		return nil
+	})
+}
+
+// insertAt inserts elem into settable Slice v at index i which must be in
+// range [0, v.Len()] and records the change in j. The backing array of v is
+// not modified.
+func insertAt(v reflect.Value, i int, elem reflect.Value, j *journal) {
+	var result = reflect.MakeSlice(v.Type(), 0, v.Len()+1)
+	result = reflect.AppendSlice(result, v.Slice(0, i))
+	result = reflect.Append(result, elem)
+	result = reflect.AppendSlice(result, v.Slice(i, v.Len()))
+	j.set(v, result)
+}
+
+// RemoveAt removes an element from a Slice inside a Go compound value
+// specified by root at the index specified by path which must end with an
+// index. Elements after it are shifted. Root must be a pointer.
+//
+// For example:
+//
+//	Hosts[2]
+func RemoveAt(path string, root interface{}) (err error) {
+	defer recoverError(&err)
+	return modifyContainer(path, root, func(v reflect.Value, key string) error {
+		if v.Kind() != reflect.Slice {
+			return ErrInvalidPath
+		}
+		return removeAt(v, key, nil)
+	})
+}
+
+// removeAt removes an element at index specified by key from Slice v and
+// records the change in j. The backing array of v is not modified.
+func removeAt(v reflect.Value, key string, j *journal) error {
+	if !v.CanSet() {
+		return ErrUnaddressableValue
+	}
+	var i, err = parseIndex(key, v.Len(), false)
+	if err != nil {
+		return err
+	}
+	var result = reflect.MakeSlice(v.Type(), 0, v.Len()-1)
+	result = reflect.AppendSlice(result, v.Slice(0, i))
+	result = reflect.AppendSlice(result, v.Slice(i+1, v.Len()))
+	j.set(v, result)
+	return nil
+}
+
+// newResolveError returns a new *ResolveError for a failure of err resolving
+// element i of segs on value v found at the prefix before it.
+func newResolveError(segs []segment, i int, v reflect.Value, err error) *ResolveError {
+	var e = &ResolveError{
+		Path:    segmentsToPath(segs),
+		Element: strings.TrimPrefix(segmentsToPath(segs[i:i+1]), "."),
+		Prefix:  segmentsToPath(segs[:i]),
+		Err:     err,
+	}
+	if !v.IsValid() {
+		e.Kind = reflect.Interface
+		return e
+	}
+	e.Kind, e.Type = v.Kind(), v.Type()
+	if segs[i].token == NameToken && v.Kind() == reflect.Struct {
+		if _, ok := v.Type().FieldByName(segs[i].name); !ok {
+			e.Suggestion = suggestField(v.Type(), segs[i].name)
+		}
+	}
+	return e
+}
+
+// suggestField returns the name of the exported field of struct type t,
+// including promoted fields, closest to name by case insensitive edit
+// distance, or an empty string if no field name is close enough.
+func suggestField(t reflect.Type, name string) (suggestion string) {
+	var best = (len(name)+1)/3 + 1
+	if best < 2 {
+		best = 2
+	}
+	var lower = strings.ToLower(name)
+	var visit func(t reflect.Type, depth int)
+	visit = func(t reflect.Type, depth int) {
+		for i := 0; i < t.NumField(); i++ {
+			var field = t.Field(i)
+			if field.PkgPath == "" {
+				if d := editDistance(lower, strings.ToLower(field.Name)); d < best {
+					best, suggestion = d, field.Name
+				}
+			}
+			if !field.Anonymous || depth > 8 {
+				continue
+			}
+			var ft = field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				visit(ft, depth+1)
+			}
+		}
+	}
+	visit(t, 0)
+	return
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	var ra, rb = []rune(a), []rune(b)
+	var prev, curr = make([]int, len(rb)+1), make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			var cost = 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// min3 returns the smallest of a, b and c.
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
 	}
+	return a
 }
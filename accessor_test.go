@@ -5,6 +5,7 @@
 package strconvex
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -193,6 +194,74 @@ func TestPath(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name:     "Wildcards",
+			TestPath: "*.Servers[*][*].**.Hosts[1:3][:]",
+			Results: []PathTestResult{
+				{
+					Element: "*",
+					Token:   WildcardToken,
+				},
+				{
+					Element: "Servers[*]",
+					Token:   KeyedNameToken,
+				},
+				{
+					Element: "[*]",
+					Token:   WildcardToken,
+				},
+				{
+					Element: "**",
+					Token:   RecursiveToken,
+				},
+				{
+					Element: "Hosts[1:3]",
+					Token:   KeyedNameToken,
+				},
+				{
+					Element: "[:]",
+					Token:   RangeToken,
+				},
+			},
+		},
+		{
+			Name:     "QuotedWildcard",
+			TestPath: `["*"]["1:3"]`,
+			Results: []PathTestResult{
+				{
+					Element: `["*"]`,
+					Token:   KeyToken,
+				},
+				{
+					Element: `["1:3"]`,
+					Token:   KeyToken,
+				},
+			},
+		},
+		{
+			Name:     "QuotedKeys",
+			TestPath: `Map["a.b[c]"]["\"]"]`,
+			Results: []PathTestResult{
+				{
+					Element: `Map["a.b[c]"]`,
+					Token:   KeyedNameToken,
+				},
+				{
+					Element: `["\"]"]`,
+					Token:   KeyToken,
+				},
+			},
+		},
+		{
+			Name:     "UnterminatedQuote",
+			TestPath: `Map["a]`,
+			Results: []PathTestResult{
+				{
+					Element: "",
+					Token:   InvalidToken,
+				},
+			},
+		},
 	}
 
 	for i := 0; i < len(tests); i++ {
@@ -408,4 +477,482 @@ func TestSet(t *testing.T) {
 	if s != "Foo" {
 		t.Fatal("Set failed.")
 	}
-}
\ No newline at end of file
+}
+
+type Modify struct {
+	Labels map[string]string
+	Ports  []int
+	Hosts  []string
+	Nested map[string][]string
+	Child  *Child
+}
+
+func TestSetMapElement(t *testing.T) {
+	var data = getData()
+	var err error
+	if err = Set("Map[Three].String", "Foo", data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Map["Three"].String != "Foo" {
+		t.Fatal("Set failed.")
+	}
+	var val = &Modify{}
+	if err = Set("Labels[env]", "prod", val); err != nil {
+		t.Fatal(err)
+	}
+	if val.Labels["env"] != "prod" {
+		t.Fatal("Set failed.")
+	}
+	if err = Set("Child.Int", "42", val); err != nil {
+		t.Fatal(err)
+	}
+	if val.Child == nil || val.Child.Int != 42 {
+		t.Fatal("Set failed.")
+	}
+	if err = Set("Int", "1", *data); err != ErrUnaddressableValue {
+		t.Fatal("Set failed.")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	var val = &Modify{
+		Labels: map[string]string{"env": "prod", "app": "web"},
+		Hosts:  []string{"a", "b", "c"},
+	}
+	var err error
+	if err = Delete("Labels[env]", val); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := val.Labels["env"]; ok || len(val.Labels) != 1 {
+		t.Fatal("Delete failed.")
+	}
+	if err = Delete("Labels[env]", val); !errors.Is(err, ErrInvalidPath) {
+		t.Fatal("Delete failed.")
+	}
+	if err = Delete("Hosts[1]", val); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(val.Hosts, []string{"a", "c"}) {
+		t.Fatal("Delete failed.")
+	}
+	if err = Delete("Labels", val); err != ErrInvalidPath {
+		t.Fatal("Delete failed.")
+	}
+}
+
+func TestAppend(t *testing.T) {
+	var val = &Modify{
+		Nested: map[string][]string{"web": {"a"}},
+	}
+	var err error
+	if err = Append("Ports", "8080", val); err != nil {
+		t.Fatal(err)
+	}
+	if err = Append("Ports", "8081", val); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(val.Ports, []int{8080, 8081}) {
+		t.Fatal("Append failed.")
+	}
+	if err = Append("Nested[web]", "b", val); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(val.Nested["web"], []string{"a", "b"}) {
+		t.Fatal("Append failed.")
+	}
+	if err = Append("Ports", "foo", val); err == nil {
+		t.Fatal("Append failed.")
+	}
+	if err = Append("Labels", "foo", val); err != ErrInvalidPath {
+		t.Fatal("Append failed.")
+	}
+}
+
+func TestInsert(t *testing.T) {
+	var val = &Modify{
+		Hosts: []string{"b", "c"},
+	}
+	var err error
+	if err = Insert("Hosts[0]", "a", val); err != nil {
+		t.Fatal(err)
+	}
+	if err = Insert("Hosts[3]", "d", val); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(val.Hosts, []string{"a", "b", "c", "d"}) {
+		t.Fatal("Insert failed.")
+	}
+	if err = Insert("Hosts[5]", "e", val); !errors.Is(err, ErrInvalidPath) {
+		t.Fatal("Insert failed.")
+	}
+}
+
+func TestRemoveAt(t *testing.T) {
+	var val = &Modify{
+		Hosts:  []string{"a", "b", "c"},
+		Nested: map[string][]string{"web": {"a", "b"}},
+	}
+	var err error
+	if err = RemoveAt("Hosts[2]", val); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(val.Hosts, []string{"a", "b"}) {
+		t.Fatal("RemoveAt failed.")
+	}
+	if err = RemoveAt("Nested[web][0]", val); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(val.Nested["web"], []string{"b"}) {
+		t.Fatal("RemoveAt failed.")
+	}
+	if err = RemoveAt("Hosts[2]", val); !errors.Is(err, ErrInvalidPath) {
+		t.Fatal("RemoveAt failed.")
+	}
+}
+
+type ModifyPtr struct {
+	Modify   *Modify
+	Embedded struct{ *Child }
+}
+
+func TestModifyFailure(t *testing.T) {
+	var val = &ModifyPtr{}
+	if err := Set("Modify.Child.Int", "foo", val); err == nil {
+		t.Fatal("Set failed.")
+	}
+	if val.Modify != nil {
+		t.Fatal("Set failed.")
+	}
+	if err := Set("Embedded.Int", "foo", val); err == nil {
+		t.Fatal("Set failed.")
+	}
+	if val.Embedded.Child != nil {
+		t.Fatal("Set failed.")
+	}
+	if err := Set("Modify.Nested[web][0]", "a", val); err == nil {
+		t.Fatal("Set failed.")
+	}
+	if val.Modify != nil {
+		t.Fatal("Set failed.")
+	}
+	if err := Delete("Modify.Labels[env]", val); !errors.Is(err, ErrInvalidPath) {
+		t.Fatal("Delete failed.")
+	}
+	if err := RemoveAt("Modify.Hosts[0]", val); !errors.Is(err, ErrInvalidPath) {
+		t.Fatal("RemoveAt failed.")
+	}
+	if err := Insert("Modify.Hosts[1]", "a", val); !errors.Is(err, ErrInvalidPath) {
+		t.Fatal("Insert failed.")
+	}
+	if val.Modify != nil {
+		t.Fatal("Modify failed.")
+	}
+	val.Modify = &Modify{Labels: map[string]string{"env": "prod"}}
+	if err := Set("Modify.Nested[web]", "a,b", val); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(val.Modify.Nested, map[string][]string{"web": {"a", "b"}}) {
+		t.Fatal("Set failed.")
+	}
+	if err := Delete("Modify.Nested[app][0]", val); !errors.Is(err, ErrInvalidPath) {
+		t.Fatal("Delete failed.")
+	}
+	if _, ok := val.Modify.Nested["app"]; ok {
+		t.Fatal("Delete failed.")
+	}
+}
+
+func TestFindNegativeIndex(t *testing.T) {
+	var val reflect.Value
+	var err error
+	if val, err = Find("Slice[-1].String", getData()); err != nil {
+		t.Fatal(err)
+	}
+	if val.String() != "Five" {
+		t.Fatal("Find failed.")
+	}
+	if val, err = Find("Array[-5].String", getData()); err != nil {
+		t.Fatal(err)
+	}
+	if val.String() != "One" {
+		t.Fatal("Find failed.")
+	}
+	var val2 = &Modify{Hosts: []string{"a", "b", "c"}}
+	if err = RemoveAt("Hosts[-1]", val2); err != nil {
+		t.Fatal(err)
+	}
+	if err = Insert("Hosts[-1]", "c", val2); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(val2.Hosts, []string{"a", "c", "b"}) {
+		t.Fatal("Insert failed.")
+	}
+}
+
+func TestIndexError(t *testing.T) {
+	var err error
+	var ie *IndexError
+	for _, path := range []string{"Slice[5]", "Slice[-6]", "Array[5]"} {
+		if _, err = Find(path, getData()); !errors.As(err, &ie) {
+			t.Fatalf("Find(%s) failed: want *IndexError, got %v", path, err)
+		}
+		if ie.Length != 5 {
+			t.Fatal("IndexError failed.")
+		}
+		if !errors.Is(err, ErrInvalidPath) {
+			t.Fatal("IndexError failed.")
+		}
+	}
+}
+
+func TestFindIndexNoPanic(t *testing.T) {
+	var keys = []string{
+		"0", "-0", "+1", "-1", "4", "5", "-5", "-6", "2147483648", "-2147483649",
+		"9223372036854775807", "-9223372036854775808", "99999999999999999999",
+		"1.5", "0x1", "", " 1", "a", "*", "1:2", "-1:", `"1"`,
+	}
+	for _, key := range keys {
+		for _, field := range []string{"Slice", "Array", "Map"} {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Fatalf("Find(%s[%s]) panicked: %v", field, key, r)
+					}
+				}()
+				Find(field+"["+key+"]", getData())
+			}()
+		}
+	}
+}
+
+func TestPathSyntaxError(t *testing.T) {
+	var tests = []struct {
+		Path     string
+		Offset   int
+		Char     string
+		Expected string
+	}{
+		{"Servers[web.Port", 11, ".", "']'"},
+		{"Servers[web", 11, "", "']'"},
+		{"Servers[[0]]", 8, "[", "']'"},
+		{"Servers]", 7, "]", "'[' before ']'"},
+		{"Servers[]", 8, "]", "key"},
+		{"A..B", 2, ".", "name"},
+		{"A.B.", 4, "", "name"},
+		{`Map["web]`, 4, `"`, "closed quoted key"},
+		{`Map["\z"]`, 4, `"`, "valid quoted key"},
+		{`Map[a]["\z"]`, 7, `"`, "valid quoted key"},
+	}
+	for _, test := range tests {
+		var _, err = Find(test.Path, getData())
+		var pse *PathSyntaxError
+		if !errors.As(err, &pse) {
+			t.Fatalf("Find(%s) failed: want *PathSyntaxError, got %v", test.Path, err)
+		}
+		if pse.Offset != test.Offset || pse.Char != test.Char || pse.Expected != test.Expected {
+			t.Fatalf("Find(%s) failed: want %d %q %s, got %d %q %s", test.Path,
+				test.Offset, test.Char, test.Expected, pse.Offset, pse.Char, pse.Expected)
+		}
+		if !errors.Is(err, ErrInvalidPath) {
+			t.Fatal("PathSyntaxError failed.")
+		}
+		if err = Set(test.Path, "", getData()); !errors.As(err, &pse) {
+			t.Fatalf("Set(%s) failed: want *PathSyntaxError, got %v", test.Path, err)
+		}
+	}
+	var path = Parse("A.[")
+	for {
+		if _, token := path.Next(); token == InvalidToken {
+			break
+		}
+	}
+	if err := path.Err(); err == nil || err.Error() != `strconvex: invalid path: syntax error at offset 3: unexpected end of path, expected ']'` {
+		t.Fatal("Path.Err failed.", err)
+	}
+	if err := Parse("A").Err(); err != nil {
+		t.Fatal("Path.Err failed.")
+	}
+}
+
+func TestResolveError(t *testing.T) {
+	var tests = []struct {
+		Path       string
+		Element    string
+		Prefix     string
+		Kind       reflect.Kind
+		Suggestion string
+	}{
+		{"Slice[1].Strng", "Strng", "Slice[1]", reflect.Struct, "String"},
+		{"Slice[1].int", "int", "Slice[1]", reflect.Struct, "Int"},
+		{"Arary[0]", "Arary", "", reflect.Struct, "Array"},
+		{"Array[9].Int", "[9]", "Array", reflect.Array, ""},
+		{"Map[Zero].Int", "[Zero]", "Map", reflect.Map, ""},
+		{"Map[One].Int.Foo", "Foo", "Map[One].Int", reflect.Int, ""},
+		{"Array[0].Bool[1]", "[1]", "Array[0].Bool", reflect.Bool, ""},
+		{"Slice[0].Unrelated", "Unrelated", "Slice[0]", reflect.Struct, ""},
+	}
+	for _, test := range tests {
+		var _, err = Find(test.Path, getData())
+		var re *ResolveError
+		if !errors.As(err, &re) {
+			t.Fatalf("Find(%s) failed: want *ResolveError, got %v", test.Path, err)
+		}
+		if re.Element != test.Element || re.Prefix != test.Prefix ||
+			re.Kind != test.Kind || re.Suggestion != test.Suggestion {
+			t.Fatalf("Find(%s) failed: want %q %q %s %q, got %q %q %s %q", test.Path,
+				test.Element, test.Prefix, test.Kind, test.Suggestion,
+				re.Element, re.Prefix, re.Kind, re.Suggestion)
+		}
+		if !errors.Is(err, ErrInvalidPath) {
+			t.Fatal("ResolveError failed.")
+		}
+		if test.Kind == reflect.Map {
+			continue
+		}
+		if err = Set(test.Path, "", getData()); !errors.As(err, &re) || re.Element != test.Element {
+			t.Fatalf("Set(%s) failed: want *ResolveError, got %v", test.Path, err)
+		}
+	}
+	var _, err = Find("Slice[1].Strng", getData())
+	if err == nil || err.Error() != `strconvex: invalid path: field not found: Strng: cannot resolve "Strng" at "Slice[1]" (struct strconvex.Child), did you mean "String"?` {
+		t.Fatal("ResolveError failed.", err)
+	}
+	var ie *IndexError
+	if _, err = Find("Array[9]", getData()); !errors.As(err, &ie) || ie.Index != 9 {
+		t.Fatal("ResolveError failed.", err)
+	}
+}
+
+type Inner struct {
+	Port int
+	Name string
+}
+
+type inner struct {
+	Secret int
+	Shared int
+}
+
+type Outer struct {
+	*Inner
+	inner
+	Port   int
+	hidden int
+}
+
+type OuterHidden struct {
+	*inner
+}
+
+func TestUnexportedField(t *testing.T) {
+	var data = &Outer{}
+	if err := Set("hidden", "1", data); !errors.Is(err, ErrUnexportedField) {
+		t.Fatal("Set failed.", err)
+	}
+	if _, err := Get("hidden", data); !errors.Is(err, ErrUnexportedField) {
+		t.Fatal("Get failed.", err)
+	}
+	var re *ResolveError
+	if _, err := Find("hidden", data); !errors.As(err, &re) || re.Element != "hidden" {
+		t.Fatal("Find failed.", err)
+	}
+	if _, err := MustCompile("hidden").Get(data); !errors.Is(err, ErrUnexportedField) {
+		t.Fatal("CompiledPath.Get failed.", err)
+	}
+	if err := Set("Secret", "1", &OuterHidden{}); !errors.Is(err, ErrUnexportedField) {
+		t.Fatal("Set failed.", err)
+	}
+}
+
+func TestEmbeddedField(t *testing.T) {
+	var data = &Outer{}
+	if _, err := Find("Name", data); !errors.Is(err, ErrInvalidPath) {
+		t.Fatal("Find failed.", err)
+	}
+	if err := Set("Name", "foo", data); err != nil || data.Inner == nil || data.Name != "foo" {
+		t.Fatal("Set failed.", err)
+	}
+	if err := Set("Port", "1", data); err != nil || data.Port != 1 || data.Inner.Port != 0 {
+		t.Fatal("Set failed.", err)
+	}
+	if err := Set("Inner.Port", "2", data); err != nil || data.Inner.Port != 2 {
+		t.Fatal("Set failed.", err)
+	}
+	if err := Set("Shared", "3", data); err != nil || data.Shared != 3 {
+		t.Fatal("Set failed.", err)
+	}
+	if v, err := Get("Shared", data); err != nil || v != 3 {
+		t.Fatal("Get failed.", err)
+	}
+	if _, err := Get("inner", data); !errors.Is(err, ErrUnexportedField) {
+		t.Fatal("Get failed.", err)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"", "A", "A.B", "A[0]", "[0]", "A[-1].B", `A["x.y"]`, `A['[]']`, "A[*]",
+		"**.B", "A[1:3]", "A..B", "A[", "A]", `A["\z"]`, "Servers[web.Port",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, path string) {
+		var p = Parse(path)
+		for i := 0; i <= len(path)+1; i++ {
+			var _, token = p.Next()
+			if token == NoToken || token == InvalidToken {
+				break
+			}
+		}
+		if errors.Is(p.Err(), errPanic) {
+			t.Fatalf("Next(%q) panicked: %v", path, p.Err())
+		}
+		var elements, err = Parse(path).Elements()
+		if errors.Is(err, errPanic) {
+			t.Fatalf("Elements(%q) panicked: %v", path, err)
+		}
+		if err != nil {
+			return
+		}
+		var canonical = Parse(path).String()
+		var reparsed, rerr = Parse(canonical).Elements()
+		if rerr != nil {
+			t.Fatalf("Parse(%q) of canonical %q failed: %v", path, canonical, rerr)
+		}
+		if !reflect.DeepEqual(elements, reparsed) {
+			t.Fatalf("Parse(%q) not stable: %v, canonical %q: %v", path, elements, canonical, reparsed)
+		}
+		if again := Parse(canonical).String(); again != canonical {
+			t.Fatalf("String(%q) not stable: %q, then %q", path, canonical, again)
+		}
+	})
+}
+
+func FuzzFind(f *testing.F) {
+	for _, seed := range []struct{ Path, Value string }{
+		{"", ""}, {"Int", "1"}, {"Array[0].Int", "2"}, {"Array[-1]", "{Int=1}"},
+		{"Slice[9]", "x"}, {"Map[One].String", "foo"}, {"Map[New]", "{Bool=true}"},
+		{"Child.Bool", "true"}, {"Name", "foo"}, {"hidden", "1"}, {"Shared", "1"},
+		{"Inner.Port", "80"}, {"Secret", "1"}, {"Slice[*]", "1"},
+	} {
+		f.Add(seed.Path, seed.Value)
+	}
+	f.Fuzz(func(t *testing.T, path, value string) {
+		for _, root := range []interface{}{getData(), &Outer{}, &OuterHidden{}, &Typed{}, new(int), (*Root)(nil)} {
+			var check = func(name string, err error) {
+				if errors.Is(err, errPanic) {
+					t.Fatalf("%s(%q, %q, %T) panicked: %v", name, path, value, root, err)
+				}
+			}
+			var _, err = Find(path, root)
+			check("Find", err)
+			_, err = Get(path, root)
+			check("Get", err)
+			check("Set", Set(path, value, root))
+			check("Append", Append(path, value, root))
+			check("Insert", Insert(path, value, root))
+			check("RemoveAt", RemoveAt(path, root))
+			check("Delete", Delete(path, root))
+		}
+	})
+}
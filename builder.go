@@ -0,0 +1,68 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"fmt"
+	"go/token"
+	"strconv"
+)
+
+// PathBuilder builds paths in canonical form element by element, quoting
+// keys as needed. The zero value is an empty builder ready to use.
+//
+// For example:
+//
+//	var path = new(PathBuilder).Field("Servers").Key("web.1").Field("Ports").Index(0).String()
+//
+// produces:
+//
+//	Servers["web.1"].Ports[0]
+type PathBuilder struct {
+	segs []segment
+	err  error
+}
+
+// Field appends a struct field name element. Name must be a Go identifier.
+func (b *PathBuilder) Field(name string) *PathBuilder {
+	if name == "" && b.err == nil {
+		b.err = fmt.Errorf("%w: empty field name", ErrInvalidArgument)
+	} else if !token.IsIdentifier(name) && b.err == nil {
+		b.err = fmt.Errorf("%w: invalid field name: %q", ErrInvalidArgument, name)
+	}
+	b.segs = append(b.segs, segment{token: NameToken, name: name})
+	return b
+}
+
+// Index appends an Array or Slice index element.
+func (b *PathBuilder) Index(index int) *PathBuilder {
+	b.segs = append(b.segs, segment{token: KeyToken, key: strconv.Itoa(index)})
+	return b
+}
+
+// Key appends a Map key element. Key is converted to a string by
+// InterfaceToString.
+func (b *PathBuilder) Key(key interface{}) *PathBuilder {
+	var s, err = InterfaceToString(key)
+	if err != nil && b.err == nil {
+		b.err = err
+	}
+	b.segs = append(b.segs, segment{token: KeyToken, key: s})
+	return b
+}
+
+// Build returns the built path or the first error that occurred while
+// building it.
+func (b *PathBuilder) Build() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+	return segmentsToPath(b.segs), nil
+}
+
+// String returns the built path, ignoring errors.
+func (b *PathBuilder) String() string {
+	return segmentsToPath(b.segs)
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPathBuilder(t *testing.T) {
+	var tests = []struct {
+		Builder *PathBuilder
+		Expect  string
+	}{
+		{new(PathBuilder).Field("Slice").Index(1).Field("String"), "Slice[1].String"},
+		{new(PathBuilder).Key("Three").Field("Int"), "[Three].Int"},
+		{new(PathBuilder).Field("Map").Key("a.b[c]"), `Map["a.b[c]"]`},
+		{new(PathBuilder).Field("Map").Key(`"quoted"`), `Map["\"quoted\""]`},
+		{new(PathBuilder).Field("Map").Key(""), `Map[""]`},
+		{new(PathBuilder).Field("Map").Key("*"), `Map["*"]`},
+		{new(PathBuilder).Field("Map").Key(42).Index(-1), "Map[42][-1]"},
+	}
+	for _, test := range tests {
+		var path, err = test.Builder.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path != test.Expect {
+			t.Fatalf("PathBuilder failed: want %s, got %s", test.Expect, path)
+		}
+		if s := Parse(path).String(); s != path {
+			t.Fatalf("Path.String failed: want %s, got %s", path, s)
+		}
+	}
+	if _, err := new(PathBuilder).Field("Map").Key(make(chan int)).Build(); err == nil {
+		t.Fatal("PathBuilder failed.")
+	}
+	if _, err := new(PathBuilder).Field("").Build(); err == nil {
+		t.Fatal("PathBuilder failed.")
+	}
+	for _, name := range []string{"a.b", "a[0]", "a]", `"a"`, "*", "1a", "a b"} {
+		if _, err := new(PathBuilder).Field(name).Build(); !errors.Is(err, ErrInvalidArgument) {
+			t.Fatalf("PathBuilder failed: %q", name)
+		}
+	}
+	if _, err := new(PathBuilder).Field("Ünïcode_1").Build(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPathBuilderFind(t *testing.T) {
+	var data = map[string]map[int]string{"a.b[c]": {1: "found"}}
+	var val, err = Get(new(PathBuilder).Key("a.b[c]").Key(1).String(), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val != "found" {
+		t.Fatal("PathBuilder failed.")
+	}
+}
+
+func TestPathString(t *testing.T) {
+	var tests = []struct {
+		Path   string
+		Expect string
+	}{
+		{".Field1.Field2", "Field1.Field2"},
+		{"Slice[1][Key].Field", "Slice[1][Key].Field"},
+		{`Map["plain"]`, "Map[plain]"},
+		{`Map["a.b"].X`, `Map["a.b"].X`},
+		{`Map["]"]`, `Map["]"]`},
+		{"Servers[*].Port", "Servers[*].Port"},
+		{"*.Port", "[*].Port"},
+		{"**.Timeout", "**.Timeout"},
+		{"**[1:3]", "**[1:3]"},
+		{`["*"]`, `["*"]`},
+		{"Field1.", "Field1."},
+		{".[0]", "[0]"},
+		{"A.[0].B", "A[0].B"},
+	}
+	for _, test := range tests {
+		var s = Parse(test.Path).String()
+		if s != test.Expect {
+			t.Fatalf("Path.String(%s) failed: want %s, got %s", test.Path, test.Expect, s)
+		}
+		if again := Parse(s).String(); again != s {
+			t.Fatalf("Path.String(%s) is not stable: got %s", s, again)
+		}
+	}
+}
+
+func TestPathElements(t *testing.T) {
+	var elements, err = Parse(`Servers["a.b"][*].Ports[1:]`).Elements()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expect = []Element{
+		{Token: NameToken, Name: "Servers"},
+		{Token: KeyToken, Key: "a.b"},
+		{Token: WildcardToken},
+		{Token: NameToken, Name: "Ports"},
+		{Token: RangeToken, Key: "1:"},
+	}
+	if !reflect.DeepEqual(elements, expect) {
+		t.Fatalf("Path.Elements failed: want %v, got %v", expect, elements)
+	}
+	if _, err = Parse("[").Elements(); err == nil {
+		t.Fatal("Path.Elements failed.")
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// BytesTag is the struct tag key of the encoding of []byte and [N]byte
+// fields. Encodings are:
+//
+//	raw        the bytes of the string as is
+//	hex        hexadecimal, optionally prefixed with "0x"
+//	base64     standard base64, optionally prefixed with "base64:"
+//	base64url  URL and file name safe base64, optionally prefixed with
+//	           "base64:"
+//	auto       hex if prefixed with "0x", standard base64 if prefixed with
+//	           "base64:" and raw otherwise
+//
+// Base64 padding is optional. Fields are converted to strings in the same
+// encoding, without a prefix except for auto which emits hex prefixed with
+// "0x", and without base64 padding as the equals sign is reserved by
+// StringToValue for compound values. Raw fields that contain characters
+// reserved for compound values, a comma, an equals sign or braces, fail to
+// convert. For example:
+//
+//	Key  [32]byte `bytes:"hex"`
+//	Salt []byte   `bytes:"base64"`
+//
+// The tag applies to struct fields only; when decoding structs and when
+// setting fields by Set, SetPointer and CompiledPath.Set. Other []byte and
+// [N]byte values, such as top level values, elements of Arrays, Slices and
+// Maps and fields without the tag, are converted from hex prefixed with
+// "0x", standard base64 prefixed with "base64:" or otherwise from a list of
+// numbers like other Arrays and Slices, so "deadbeef" must be specified as
+// "0xdeadbeef". They are converted to a list of numbers. A Decoder decodes
+// them using its BytesEncoding instead, if specified.
+const BytesTag = "bytes"
+
+// bytesEncoding is an encoding of []byte and [N]byte values.
+type bytesEncoding int
+
+const (
+	// bytesList is the encoding of untagged values.
+	bytesList bytesEncoding = iota
+	bytesAuto
+	bytesRaw
+	bytesHex
+	bytesBase64
+	bytesBase64URL
+)
+
+// bytesEncodings maps BytesTag values to encodings.
+var bytesEncodings = map[string]bytesEncoding{
+	"auto":      bytesAuto,
+	"raw":       bytesRaw,
+	"hex":       bytesHex,
+	"base64":    bytesBase64,
+	"base64url": bytesBase64URL,
+}
+
+// byteType is the type of byte.
+var byteType = reflect.TypeOf(byte(0))
+
+// isBytes returns true if t is a []byte or a [N]byte.
+func isBytes(t reflect.Type) bool {
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem() == byteType
+}
+
+// fieldBytesEncoding returns the encoding in the BytesTag of []byte or
+// [N]byte struct field sf and true or false if sf is not such a field or has
+// no tag.
+func fieldBytesEncoding(sf reflect.StructField) (bytesEncoding, bool, error) {
+	var tag, ok = sf.Tag.Lookup(BytesTag)
+	if !ok || !isBytes(sf.Type) {
+		return bytesList, false, nil
+	}
+	var enc, known = bytesEncodings[tag]
+	if !known {
+		return bytesList, false, fmt.Errorf("%w: field %s: unknown bytes encoding %q", ErrInvalidArgument, sf.Name, tag)
+	}
+	return enc, true, nil
+}
+
+// fieldDecoder returns the decoding plan of struct field sf which is the
+// plan of its type unless it is a []byte or a [N]byte with a BytesTag.
+func fieldDecoder(sf reflect.StructField) decodeFunc {
+	var enc, ok, err = fieldBytesEncoding(sf)
+	if err != nil {
+		return func(s *decodeState, in string, out reflect.Value) error {
+			return err
+		}
+	}
+	if !ok {
+		return decoderFor(sf.Type)
+	}
+	return func(s *decodeState, in string, out reflect.Value) error {
+		return decodeBytes(s, enc, in, out, nil)
+	}
+}
+
+// newBytesDecoder returns the decoding plan of an untagged []byte or [N]byte
+// type that uses the encoding of state s and falls back to list.
+func newBytesDecoder(list decodeFunc) decodeFunc {
+	return func(s *decodeState, in string, out reflect.Value) error {
+		var enc, err = s.bytesEncoding()
+		if err != nil {
+			return err
+		}
+		return decodeBytes(s, enc, in, out, list)
+	}
+}
+
+// decodeBytes converts in encoded with enc to []byte or [N]byte out. List
+// converts unprefixed input if enc is bytesList.
+func decodeBytes(s *decodeState, enc bytesEncoding, in string, out reflect.Value, list decodeFunc) error {
+	if enc == bytesList || enc == bytesAuto {
+		switch {
+		case strings.HasPrefix(in, "0x"):
+			enc = bytesHex
+		case strings.HasPrefix(in, "base64:"):
+			enc = bytesBase64
+		case enc == bytesList:
+			return list(s, in, out)
+		default:
+			enc = bytesRaw
+		}
+	}
+	if err := s.checkString(in); err != nil {
+		return err
+	}
+	if err := s.alloc(1); err != nil {
+		return err
+	}
+	var b []byte
+	var err error
+	switch enc {
+	case bytesRaw:
+		b = []byte(in)
+	case bytesHex:
+		b, err = hex.DecodeString(strings.TrimPrefix(in, "0x"))
+	case bytesBase64:
+		b, err = base64.RawStdEncoding.DecodeString(trimBase64(in))
+	case bytesBase64URL:
+		b, err = base64.RawURLEncoding.DecodeString(trimBase64(in))
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidValue, err)
+	}
+	if out.Kind() == reflect.Slice {
+		out.SetBytes(b)
+		return nil
+	}
+	if len(b) != out.Len() {
+		return fmt.Errorf("%w: %d bytes for %s", ErrInvalidValue, len(b), out.Type())
+	}
+	reflect.Copy(out, reflect.ValueOf(b))
+	return nil
+}
+
+// trimBase64 trims the "base64:" prefix and padding from base64 in.
+func trimBase64(in string) string {
+	return strings.TrimRight(strings.TrimPrefix(in, "base64:"), "=")
+}
+
+// bytesToString converts []byte or [N]byte in to a string encoded with enc.
+// Raw bytes containing characters reserved for compound values result in an
+// error.
+func bytesToString(enc bytesEncoding, in reflect.Value) (string, error) {
+	var b = make([]byte, in.Len())
+	reflect.Copy(reflect.ValueOf(b), in)
+	switch enc {
+	case bytesRaw:
+		if i := strings.IndexAny(string(b), ",={}"); i >= 0 {
+			return "", fmt.Errorf("%w: raw bytes contain reserved character %q", ErrUnsupportedValue, b[i])
+		}
+		return string(b), nil
+	case bytesHex:
+		return hex.EncodeToString(b), nil
+	case bytesBase64:
+		return base64.RawStdEncoding.EncodeToString(b), nil
+	case bytesBase64URL:
+		return base64.RawURLEncoding.EncodeToString(b), nil
+	}
+	return "0x" + hex.EncodeToString(b), nil
+}
+
+// fieldToString converts the value of struct field sf to a string using the
+// encoding in its BytesTag, if any, at specified nesting depth.
+func fieldToString(sf reflect.StructField, in reflect.Value, depth int) (string, error) {
+	var enc, ok, err = fieldBytesEncoding(sf)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return formatValue(in, depth)
+	}
+	return bytesToString(enc, in)
+}
@@ -0,0 +1,145 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type Keys struct {
+	Raw    []byte  `bytes:"raw"`
+	Hex    [4]byte `bytes:"hex"`
+	Std    []byte  `bytes:"base64"`
+	URL    []byte  `bytes:"base64url"`
+	Auto   []byte  `bytes:"auto"`
+	List   []byte
+	Secret []byte `bytes:"hex" default:"cafe" validate:"len=2"`
+}
+
+func TestBytes(t *testing.T) {
+	var data Keys
+	var expect = Keys{
+		Raw:  []byte("text"),
+		Hex:  [4]byte{0xde, 0xad, 0xbe, 0xef},
+		Std:  []byte{0xfb, 0xff},
+		URL:  []byte{0xfb, 0xff},
+		Auto: []byte{1, 2},
+	}
+	if err := StringToInterface("{Raw=text, Hex=0xdeadbeef, Std=base64:+/8, URL=-_8, Auto=base64:AQI}", &data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(data, expect) {
+		t.Fatal("StringToInterface failed.")
+	}
+	data.List, data.Secret = []byte{7}, []byte{0xca, 0xfe}
+	var s, err = InterfaceToString(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "{Raw=text,Hex=deadbeef,Std=+/8,URL=-_8,Auto=0x0102,List=7,Secret=cafe}" {
+		t.Fatalf("InterfaceToString failed: %s", s)
+	}
+	var again Keys
+	if err = StringToInterface(s, &again); err != nil || !reflect.DeepEqual(again, data) {
+		t.Fatal("StringToInterface failed.")
+	}
+	var tests = []struct {
+		In     string
+		Expect []byte
+	}{
+		{"1,2,3", []byte{1, 2, 3}},
+		{"0x010203", []byte{1, 2, 3}},
+		{"base64:AQID", []byte{1, 2, 3}},
+		{"base64:AQ==", []byte{1}},
+		{"0x", []byte{}},
+	}
+	for _, test := range tests {
+		var b []byte
+		if err = StringToInterface(test.In, &b); err != nil {
+			t.Fatalf("StringToInterface failed: %q: %v", test.In, err)
+		}
+		if !reflect.DeepEqual(b, test.Expect) {
+			t.Fatalf("StringToInterface failed: %q: %v", test.In, b)
+		}
+	}
+	var a [2]byte
+	if err = StringToInterface("0x0102", &a); err != nil || a != [2]byte{1, 2} {
+		t.Fatal("StringToInterface failed.")
+	}
+	if err = StringToInterface("0x010203", &a); !errors.Is(err, ErrInvalidValue) {
+		t.Fatal("StringToInterface failed.")
+	}
+	if err = StringToInterface("0xzz", &a); !errors.Is(err, ErrInvalidValue) {
+		t.Fatal("StringToInterface failed.")
+	}
+}
+
+func TestBytesField(t *testing.T) {
+	var data Keys
+	if err := Set("Hex", "cafebabe", &data); err != nil || data.Hex != [4]byte{0xca, 0xfe, 0xba, 0xbe} {
+		t.Fatal("Set failed.")
+	}
+	if err := Set("Hex", "cafe", &data); !errors.Is(err, ErrInvalidValue) {
+		t.Fatal("Set failed.")
+	}
+	if err := Set("Std", "AQI=", &data); err != nil || !reflect.DeepEqual(data.Std, []byte{1, 2}) {
+		t.Fatal("Set failed.")
+	}
+	if err := Set("Secret", "010203", &data); !errors.Is(err, ErrValidation) || data.Secret != nil {
+		t.Fatal("Set failed.")
+	}
+	if err := ApplyDefaults(&data); err != nil || !reflect.DeepEqual(data.Secret, []byte{0xca, 0xfe}) {
+		t.Fatal("ApplyDefaults failed.")
+	}
+	var invalid struct {
+		Key []byte `bytes:"base32"`
+	}
+	if err := StringToInterface("{Key=x}", &invalid); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal("StringToInterface failed.")
+	}
+	if _, err := InterfaceToString(invalid); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal("InterfaceToString failed.")
+	}
+}
+
+func TestDecoderBytesEncoding(t *testing.T) {
+	var b []byte
+	if err := StringToInterface("deadbeef", &b); err == nil {
+		t.Fatal("StringToInterface failed.")
+	}
+	if err := StringToInterface("0xdeadbeef", &b); err != nil || !reflect.DeepEqual(b, []byte{0xde, 0xad, 0xbe, 0xef}) {
+		t.Fatal("StringToInterface failed.")
+	}
+	var d = &Decoder{BytesEncoding: "hex"}
+	if err := d.DecodeString("deadbeef", &b); err != nil || !reflect.DeepEqual(b, []byte{0xde, 0xad, 0xbe, 0xef}) {
+		t.Fatal("DecodeString failed.", err)
+	}
+	var m map[string][]byte
+	if err := d.DecodeString("a=0102,b=ff", &m); err != nil || !reflect.DeepEqual(m, map[string][]byte{"a": {1, 2}, "b": {0xff}}) {
+		t.Fatal("DecodeString failed.", err)
+	}
+	var keys Keys
+	if err := d.DecodeString("{Std=AQI,List=0a}", &keys); err != nil ||
+		!reflect.DeepEqual(keys.Std, []byte{1, 2}) || !reflect.DeepEqual(keys.List, []byte{10}) {
+		t.Fatal("DecodeString failed.", err)
+	}
+	d = &Decoder{BytesEncoding: "base32"}
+	if err := d.DecodeString("x", &b); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal("DecodeString failed.", err)
+	}
+}
+
+func TestBytesRawReserved(t *testing.T) {
+	var keys = Keys{Raw: []byte("a,b")}
+	if _, err := InterfaceToString(keys); !errors.Is(err, ErrUnsupportedValue) {
+		t.Fatal("InterfaceToString failed.", err)
+	}
+	keys.Raw = []byte("a b")
+	if s, err := InterfaceToString(keys); err != nil || s != "{Raw=a b,Hex=00000000,Std=,URL=,Auto=0x,List=,Secret=}" {
+		t.Fatal("InterfaceToString failed.", s, err)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// CompiledPath is a path parsed once for repeated use with Find, Get and Set.
+//
+// The first time a CompiledPath is used with a root of some type it resolves
+// struct field indexes, Array and Slice indexes and Map keys along the path
+// for that type and caches them so that subsequent uses with a root of the
+// same type skip both parsing and lookups by name. Resolution stops at the
+// first interface along the path; the rest of the path is resolved on each
+// use.
+//
+// A CompiledPath is safe for concurrent use.
+type CompiledPath struct {
+	path    string
+	segs    []segment
+	plans   sync.Map // reflect.Type -> []segment
+	targets sync.Map // reflect.Type -> *setTarget
+}
+
+// Compile parses path into a CompiledPath. Path may not contain wildcards.
+func Compile(path string) (*CompiledPath, error) {
+	var segs, err = segments(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, seg := range segs {
+		if seg.token != NameToken && seg.token != KeyToken {
+			return nil, errWildcard
+		}
+	}
+	return &CompiledPath{path: path, segs: segs}, nil
+}
+
+// MustCompile is like Compile but panics on error.
+func MustCompile(path string) *CompiledPath {
+	var cp, err = Compile(path)
+	if err != nil {
+		panic(err)
+	}
+	return cp
+}
+
+// String returns the path that was compiled.
+func (cp *CompiledPath) String() string { return cp.path }
+
+// Find is like Find but uses the compiled path.
+func (cp *CompiledPath) Find(root interface{}) (reflect.Value, error) {
+	if root == nil {
+		return reflect.Value{}, ErrInvalidArgument
+	}
+	var v = reflect.ValueOf(root)
+	return find(v, cp.plan(v.Type()))
+}
+
+// Get is like Get but uses the compiled path.
+func (cp *CompiledPath) Get(root interface{}) (interface{}, error) {
+	var val, err = cp.Find(root)
+	if err != nil {
+		return nil, err
+	}
+	return val.Interface(), nil
+}
+
+// Set is like Set but uses the compiled path.
+func (cp *CompiledPath) Set(value string, root interface{}) error {
+	if root == nil {
+		return ErrInvalidArgument
+	}
+	var v = reflect.ValueOf(root)
+	var segs = cp.plan(v.Type())
+	return modify(v, segs, false, setString(value, segs, *cp.target(v.Type(), segs)))
+}
+
+// target returns the setTarget of cp in roots of type t whose bound segments
+// are segs, caching it on first use.
+func (cp *CompiledPath) target(t reflect.Type, segs []segment) *setTarget {
+	if target, ok := cp.targets.Load(t); ok {
+		return target.(*setTarget)
+	}
+	var target = newSetTarget(segs, t)
+	var cached, _ = cp.targets.LoadOrStore(t, &target)
+	return cached.(*setTarget)
+}
+
+// plan returns segments of cp bound to root type t, binding and caching them
+// on first use.
+func (cp *CompiledPath) plan(t reflect.Type) []segment {
+	if segs, ok := cp.plans.Load(t); ok {
+		return segs.([]segment)
+	}
+	var segs, _ = cp.plans.LoadOrStore(t, bind(cp.segs, t))
+	return segs.([]segment)
+}
+
+// bind returns a copy of segs with field indexes, Array and Slice indexes and
+// Map keys resolved for root type t. Segments from the first one that cannot
+// be resolved from type information alone are left unbound.
+func bind(segs []segment, t reflect.Type) []segment {
+	var result = make([]segment, len(segs))
+	copy(result, segs)
+loop:
+	for i := range result {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		switch result[i].token {
+		case NameToken:
+			if t.Kind() != reflect.Struct {
+				break loop
+			}
+			var field, ok = t.FieldByName(result[i].name)
+			if !ok || field.PkgPath != "" {
+				break loop
+			}
+			result[i].field = field.Index
+			t = field.Type
+		case KeyToken:
+			switch t.Kind() {
+			case reflect.Array, reflect.Slice:
+				var index, err = strconv.Atoi(result[i].key)
+				if err != nil {
+					break loop
+				}
+				result[i].index, result[i].indexed = index, true
+			case reflect.Map:
+				var key = reflect.New(t.Key()).Elem()
+				if err := StringToValue(result[i].key, key); err != nil {
+					break loop
+				}
+				result[i].mapkey = key
+			default:
+				break loop
+			}
+			t = t.Elem()
+		default:
+			break loop
+		}
+	}
+	return result
+}
@@ -0,0 +1,138 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+type CompileEmbedded struct {
+	Level int
+}
+
+type CompileRoot struct {
+	*CompileEmbedded
+	Root
+	Any interface{}
+}
+
+func TestCompiledPath(t *testing.T) {
+	var cp, err = Compile("Map[Three].String")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		var intf interface{}
+		if intf, err = cp.Get(getData()); err != nil {
+			t.Fatal(err)
+		}
+		if intf != "Three" {
+			t.Fatal("CompiledPath.Get failed.")
+		}
+	}
+	var data = getData()
+	if err = cp.Set("Foo", data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Map["Three"].String != "Foo" {
+		t.Fatal("CompiledPath.Set failed.")
+	}
+	if err = MustCompile("Slice[-1].Int").Set("42", data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Slice[4].Int != 42 {
+		t.Fatal("CompiledPath.Set failed.")
+	}
+	if _, err = MustCompile("Slice[5]").Find(data); !errors.Is(err, ErrInvalidPath) {
+		t.Fatal("CompiledPath.Find failed.")
+	}
+	if _, err = MustCompile("Map[Six]").Find(data); !errors.Is(err, ErrInvalidPath) {
+		t.Fatal("CompiledPath.Find failed.")
+	}
+	if _, err = Compile("Slice[*]"); !errors.Is(err, ErrInvalidPath) {
+		t.Fatal("Compile failed.")
+	}
+}
+
+func TestCompiledPathTypes(t *testing.T) {
+	var cp = MustCompile("Level")
+	var root = &CompileRoot{}
+	if _, err := cp.Find(root); !errors.Is(err, ErrInvalidPath) {
+		t.Fatal("CompiledPath.Find failed.")
+	}
+	if err := cp.Set("3", root); err != nil {
+		t.Fatal(err)
+	}
+	if root.Level != 3 {
+		t.Fatal("CompiledPath.Set failed.")
+	}
+	if _, err := cp.Find(getData()); !errors.Is(err, ErrInvalidPath) {
+		t.Fatal("CompiledPath.Find failed.")
+	}
+	cp = MustCompile("Any.Int")
+	for _, v := range []interface{}{Child{Int: 1}, &Child{Int: 1}} {
+		var val, err = cp.Get(&CompileRoot{Any: v})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if val != 1 {
+			t.Fatal("CompiledPath.Get failed.")
+		}
+	}
+}
+
+func TestCompiledPathConcurrent(t *testing.T) {
+	var cp = MustCompile("Array[2].String")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if val, err := cp.Get(getData()); err != nil || val != "Three" {
+					t.Error("CompiledPath.Get failed.", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkFind(b *testing.B) {
+	var data = getData()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Find("Map[Three].String", data)
+	}
+}
+
+func BenchmarkCompiledPathFind(b *testing.B) {
+	var data = getData()
+	var cp = MustCompile("Map[Three].String")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cp.Find(data)
+	}
+}
+
+func BenchmarkSet(b *testing.B) {
+	var data = getData()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Set("Slice[3].Int", "42", data)
+	}
+}
+
+func BenchmarkCompiledPathSet(b *testing.B) {
+	var data = getData()
+	var cp = MustCompile("Slice[3].Int")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cp.Set("42", data)
+	}
+}
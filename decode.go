@@ -0,0 +1,450 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// decodeFunc converts string in to out. State s carries the options and
+// limits of a Decoder and may be nil.
+type decodeFunc func(s *decodeState, in string, out reflect.Value) error
+
+// decoders caches a decodeFunc per type.
+var decoders sync.Map // reflect.Type -> decodeFunc
+
+// decoderFor returns the decoding plan for values of type t, building and
+// caching it on first use. The plan resolves the conversion by kind, the
+// encoding.TextUnmarshaler check and the plans of element types once so that
+// repeated decoding of the same type only does the conversion itself.
+func decoderFor(t reflect.Type) decodeFunc {
+	if f, ok := decoders.Load(t); ok {
+		return f.(decodeFunc)
+	}
+	// Store a forwarding plan first so that recursive types find it while
+	// their plan is being built.
+	var wg sync.WaitGroup
+	var f decodeFunc
+	wg.Add(1)
+	var fi, loaded = decoders.LoadOrStore(t, decodeFunc(func(s *decodeState, in string, out reflect.Value) error {
+		wg.Wait()
+		return f(s, in, out)
+	}))
+	if loaded {
+		return fi.(decodeFunc)
+	}
+	f = newDecoder(t)
+	wg.Done()
+	decoders.Store(t, f)
+	return f
+}
+
+// decodable returns true if out can be decoded into; if it is settable or
+// decoding does not need to set it because it is an interface or implements
+// encoding.TextUnmarshaler.
+func decodable(out reflect.Value) bool {
+	return out.CanSet() || out.Kind() == reflect.Interface || out.Type().Implements(textUnmarshalerType)
+}
+
+// newDecoder builds the decoding plan for values of type t.
+func newDecoder(t reflect.Type) decodeFunc {
+	if t.Implements(textUnmarshalerType) {
+		if t.Kind() == reflect.Ptr {
+			return decodeTextPointer
+		}
+		return decodeText
+	}
+	var f = newKindDecoder(t)
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return func(s *decodeState, in string, out reflect.Value) error {
+			if out.CanAddr() {
+				if err := s.checkString(in); err != nil {
+					return err
+				}
+				return out.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(in))
+			}
+			return f(s, in, out)
+		}
+	}
+	return f
+}
+
+// newKindDecoder builds the decoding plan for values of type t by its kind.
+func newKindDecoder(t reflect.Type) decodeFunc {
+	switch t.Kind() {
+	case reflect.Bool:
+		return simpleDecoder(stringToBoolValue)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return simpleDecoder(stringToIntValue)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return simpleDecoder(stringToUintValue)
+	case reflect.Float32:
+		return simpleDecoder(stringToFloat32Value)
+	case reflect.Float64:
+		return simpleDecoder(stringToFloat64Value)
+	case reflect.Complex64:
+		return simpleDecoder(stringToComplex64Value)
+	case reflect.Complex128:
+		return simpleDecoder(stringToComplex128Value)
+	case reflect.String:
+		return decodeString
+	case reflect.Array:
+		var elem = decoderFor(t.Elem())
+		var f = func(s *decodeState, in string, out reflect.Value) error {
+			return decodeArray(s, in, out, elem)
+		}
+		if isBytes(t) {
+			return newBytesDecoder(f)
+		}
+		return f
+	case reflect.Slice:
+		var elem = decoderFor(t.Elem())
+		var f = func(s *decodeState, in string, out reflect.Value) error {
+			return decodeSlice(s, in, out, elem)
+		}
+		if isBytes(t) {
+			return newBytesDecoder(f)
+		}
+		return f
+	case reflect.Map:
+		var key, elem = decoderFor(t.Key()), decoderFor(t.Elem())
+		return func(s *decodeState, in string, out reflect.Value) error {
+			return decodeMap(s, in, out, key, elem)
+		}
+	case reflect.Struct:
+		var plan = structPlanFor(t)
+		return func(s *decodeState, in string, out reflect.Value) error {
+			return decodeStruct(s, in, out, plan)
+		}
+	case reflect.Ptr:
+		var elem = decoderFor(t.Elem())
+		return func(s *decodeState, in string, out reflect.Value) error {
+			return decodePointer(s, in, out, elem)
+		}
+	case reflect.Interface:
+		return decodeText
+	}
+	return decodeUnsupported
+}
+
+// decodeText converts in to out using encoding.TextUnmarshaler implemented
+// by out or, if out is an interface, by its dynamic value. In is limited like
+// a String.
+func decodeText(s *decodeState, in string, out reflect.Value) error {
+	if err := s.checkString(in); err != nil {
+		return err
+	}
+	if u, ok := out.Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(in))
+	}
+	return ErrUnsupportedValue
+}
+
+// decodeTextPointer is like decodeText for a Pointer out, which is
+// allocated if nil.
+func decodeTextPointer(s *decodeState, in string, out reflect.Value) error {
+	if out.IsNil() {
+		if !out.CanSet() {
+			return ErrUnaddressableValue
+		}
+		if err := s.alloc(1); err != nil {
+			return err
+		}
+		out.Set(reflect.New(out.Type().Elem()))
+	}
+	return decodeText(s, in, out)
+}
+
+// decodeUnsupported returns ErrUnsupportedValue.
+func decodeUnsupported(s *decodeState, in string, out reflect.Value) error {
+	return ErrUnsupportedValue
+}
+
+// simpleDecoder returns a decodeFunc that converts using f.
+func simpleDecoder(f func(in string, out reflect.Value) error) decodeFunc {
+	return func(s *decodeState, in string, out reflect.Value) error {
+		return f(in, out)
+	}
+}
+
+// decodeString converts in to String out.
+func decodeString(s *decodeState, in string, out reflect.Value) error {
+	if err := s.checkString(in); err != nil {
+		return err
+	}
+	return stringToStringValue(in, out)
+}
+
+// decodeArray converts a list of values in to Array out using elem to
+// convert the elements.
+func decodeArray(s *decodeState, in string, out reflect.Value, elem decodeFunc) error {
+	if err := s.enter(count(in)); err != nil {
+		return err
+	}
+	defer s.leave()
+	if err := s.alloc(1); err != nil {
+		return err
+	}
+	v := reflect.New(out.Type()).Elem()
+	sc := newScanner(in)
+	for i, l := 0, out.Len(); i < l; i++ {
+		span, ok := sc.next()
+		if !ok {
+			break
+		}
+		if err := elem(s, strings.TrimSpace(span), v.Index(i)); err != nil {
+			return err
+		}
+	}
+	out.Set(v)
+	return nil
+}
+
+// decodeSlice converts a list of values in to Slice out using elem to
+// convert the elements.
+func decodeSlice(s *decodeState, in string, out reflect.Value, elem decodeFunc) error {
+	n := count(in)
+	if err := s.enter(n); err != nil {
+		return err
+	}
+	defer s.leave()
+	if err := s.alloc(n); err != nil {
+		return err
+	}
+	parsedval := reflect.MakeSlice(reflect.SliceOf(out.Type().Elem()), n, n)
+	sc := newScanner(in)
+	for i := 0; i < n; i++ {
+		span, _ := sc.next()
+		if err := elem(s, span, parsedval.Index(i)); err != nil {
+			return err
+		}
+	}
+	out.Set(parsedval)
+	return nil
+}
+
+// decodeMap converts a list of key=value pairs in to Map out using key and
+// elem to convert the keys and the values.
+func decodeMap(s *decodeState, in string, out reflect.Value, key, elem decodeFunc) error {
+	var n = count(in)
+	if err := s.enter(n); err != nil {
+		return err
+	}
+	defer s.leave()
+	if err := s.alloc(n); err != nil {
+		return err
+	}
+	var maptype = reflect.MapOf(out.Type().Key(), out.Type().Elem())
+	var newmap = reflect.MakeMapWithSize(maptype, n)
+	// SetMapIndex copies k and v so they are reused for all pairs.
+	var k = reflect.New(maptype.Key()).Elem()
+	var v = reflect.New(maptype.Elem()).Elem()
+	var zerok, zerov = reflect.Zero(maptype.Key()), reflect.Zero(maptype.Elem())
+	for sc := newScanner(in); ; {
+		span, ok := sc.next()
+		if !ok {
+			break
+		}
+		ks, vs, ok := splitPair(strings.TrimSpace(span))
+		if !ok {
+			return errSyntax
+		}
+		k.Set(zerok)
+		if err := key(s, ks, k); err != nil {
+			return err
+		}
+		v.Set(zerov)
+		if err := elem(s, vs, v); err != nil {
+			return err
+		}
+		newmap.SetMapIndex(k, v)
+	}
+	out.Set(newmap)
+	return nil
+}
+
+// decodeStruct converts a list of field=value pairs in braces, possibly
+// empty, in to Struct out using plan to find the fields and their decoders.
+func decodeStruct(s *decodeState, in string, out reflect.Value, plan *structPlan) error {
+	in = strings.TrimPrefix(strings.TrimSuffix(in, "}"), "{")
+	if err := s.enter(count(in)); err != nil {
+		return err
+	}
+	defer s.leave()
+	var present []*fieldPlan
+	// An empty struct value, i.e. "{}", specifies no fields.
+	var empty = strings.TrimSpace(in) == ""
+	for sc := newScanner(in); !empty; {
+		span, ok := sc.next()
+		if !ok {
+			break
+		}
+		name, value, ok := splitPair(strings.TrimSpace(span))
+		if !ok {
+			return errSyntax
+		}
+		field, err := plan.field(name, s.embeddedByTypeName())
+		if err != nil {
+			return err
+		}
+		if err = s.alloc(1); err != nil {
+			return err
+		}
+		var val = reflect.New(field.typ).Elem()
+		if err = field.decode(s, value, val); err != nil {
+			return err
+		}
+		target, err := fieldByIndex(out, field.index, true)
+		if err != nil {
+			return err
+		}
+		target.Set(val)
+		if s.applyDefaults() {
+			present = append(present, field)
+		}
+	}
+	if s.applyDefaults() {
+		return applyDefaults(s, out, "", nil, func(index []int) bool {
+			for _, field := range present {
+				if equalIndex(field.index, index) {
+					return true
+				}
+			}
+			return false
+		}, nil)
+	}
+	return nil
+}
+
+// equalIndex returns true if field indexes a and b are equal.
+func equalIndex(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// decodePointer converts in to a newly allocated value using elem and sets
+// Pointer out to it.
+func decodePointer(s *decodeState, in string, out reflect.Value, elem decodeFunc) error {
+	if err := s.alloc(1); err != nil {
+		return err
+	}
+	nv := reflect.New(out.Type().Elem())
+	if err := elem(s, in, reflect.Indirect(nv)); err != nil {
+		return err
+	}
+	out.Set(nv)
+	return nil
+}
+
+// structPlan is the decoding plan of a struct type. Fields are resolved by
+// name on first use and cached.
+type structPlan struct {
+	t      reflect.Type
+	fields sync.Map // string -> *fieldPlan
+}
+
+// fieldPlan is the decoding plan of a struct field.
+type fieldPlan struct {
+	index  []int
+	typ    reflect.Type
+	decode decodeFunc
+}
+
+// structPlans caches a *structPlan per struct type.
+var structPlans sync.Map // reflect.Type -> *structPlan
+
+// structPlanFor returns the decoding plan of struct type t.
+func structPlanFor(t reflect.Type) *structPlan {
+	if plan, ok := structPlans.Load(t); ok {
+		return plan.(*structPlan)
+	}
+	var plan, _ = structPlans.LoadOrStore(t, &structPlan{t: t})
+	return plan.(*structPlan)
+}
+
+// errFieldNotFound is returned when a struct field is not found.
+var errFieldNotFound = fmt.Errorf("%w: field not found", ErrStrconvex)
+
+// field returns the plan of the field named name, including fields promoted
+// from embedded structs. If qualified is true name may be prefixed with the
+// dot delimited type names of the embedded structs that contain the field,
+// e.g. "Base.Port". Names that are not found are not cached.
+func (sp *structPlan) field(name string, qualified bool) (*fieldPlan, error) {
+	if field, ok := sp.fields.Load(name); ok {
+		var plan = field.(*fieldPlan)
+		if !qualified && strings.IndexByte(name, '.') >= 0 {
+			return nil, errFieldNotFound
+		}
+		return plan, nil
+	}
+	var sf reflect.StructField
+	var err error
+	if strings.IndexByte(name, '.') < 0 {
+		sf, err = lookupField(sp.t, name)
+	} else if qualified {
+		sf, err = qualifiedField(sp.t, name)
+	} else {
+		err = errFieldNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var field, _ = sp.fields.LoadOrStore(name, &fieldPlan{
+		index:  sf.Index,
+		typ:    sf.Type,
+		decode: fieldDecoder(sf),
+	})
+	return field.(*fieldPlan), nil
+}
+
+// lookupField returns the exported field of struct type t named name.
+func lookupField(t reflect.Type, name string) (reflect.StructField, error) {
+	var sf, ok = t.FieldByName(name)
+	if !ok {
+		return sf, errFieldNotFound
+	}
+	if sf.PkgPath != "" {
+		return sf, fmt.Errorf("%w: %s", ErrUnexportedField, name)
+	}
+	return sf, nil
+}
+
+// qualifiedField returns the exported field of struct type t named by a
+// dot delimited name whose every element but the last names an embedded
+// struct by its type name. The index of the returned field is relative to t.
+func qualifiedField(t reflect.Type, name string) (reflect.StructField, error) {
+	var index []int
+	var parts = strings.Split(name, ".")
+	for _, part := range parts[:len(parts)-1] {
+		var sf, ok = t.FieldByName(part)
+		if !ok || !sf.Anonymous {
+			return sf, errFieldNotFound
+		}
+		index = append(index, sf.Index...)
+		if t = sf.Type; t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return sf, errFieldNotFound
+		}
+	}
+	var sf, err = lookupField(t, parts[len(parts)-1])
+	if err != nil {
+		return sf, err
+	}
+	sf.Index = append(index, sf.Index...)
+	return sf, nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// DefaultTag is the struct tag key of default field values.
+const DefaultTag = "default"
+
+// ApplyDefaults sets every zero exported field of the struct ptr points to
+// that has a default tag to the tag value converted by StringToValue, e.g.:
+//
+//	Host string `default:"localhost"`
+//
+// Fields of nested structs, structs pointed to by non-nil pointers and
+// elements of Arrays and Slices are processed recursively, after their
+// containing field has been set from its own default if it was zero. Values
+// of Pointers, Slices and Maps set from a default are not processed, nor are
+// pointers to values that contain them, i.e. cycles. Errors are returned
+// prefixed with the path of the field.
+func ApplyDefaults(ptr interface{}) error {
+	var v = reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return ErrInvalidArgument
+	}
+	return applyDefaults(nil, v, "", nil, nil, nil)
+}
+
+// applyDefaults applies defaults to v found at path under state s. Index is
+// the index of v relative to the struct being decoded if v is a field of it.
+// Fields whose index skip returns true for are skipped entirely. Seen holds
+// the pointers being descended into and may be nil.
+func applyDefaults(s *decodeState, v reflect.Value, path string, index []int, skip func([]int) bool, seen map[ptrKey]bool) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		var key = ptrKey{v.Pointer(), v.Type(), 0}
+		if seen[key] {
+			return nil
+		}
+		if seen == nil {
+			seen = make(map[ptrKey]bool)
+		}
+		seen[key] = true
+		defer delete(seen, key)
+		return applyDefaults(s, v.Elem(), path, index, skip, seen)
+	case reflect.Struct:
+		var t = v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			var sf = t.Field(i)
+			if sf.PkgPath != "" && !sf.Anonymous {
+				continue
+			}
+			var fi = append(index[:len(index):len(index)], i)
+			if skip != nil && skip(fi) {
+				continue
+			}
+			var field = v.Field(i)
+			var fpath = joinName(path, sf.Name)
+			if tag, ok := sf.Tag.Lookup(DefaultTag); ok && field.CanSet() && field.IsZero() {
+				if err := decodeDefault(s, sf, tag, field); err != nil {
+					return fmt.Errorf("%s: %w", fpath, err)
+				}
+				if k := field.Kind(); k != reflect.Struct && k != reflect.Array {
+					continue
+				}
+			}
+			if err := applyDefaults(s, field, fpath, fi, skip, seen); err != nil {
+				return err
+			}
+		}
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := applyDefaults(s, v.Index(i), joinKey(path, strconv.Itoa(i)), nil, nil, seen); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// decodeDefault decodes default tag value tag of struct field sf into field
+// under state s without applying defaults to the decoded value.
+func decodeDefault(s *decodeState, sf reflect.StructField, tag string, field reflect.Value) error {
+	if s != nil {
+		s.defaults++
+		defer func() { s.defaults-- }()
+	}
+	return fieldDecoder(sf)(s, tag, field)
+}
@@ -0,0 +1,141 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type DefaultsTLS struct {
+	Cert string `default:"cert.pem"`
+}
+
+type DefaultsBase struct {
+	Region string `default:"eu"`
+}
+
+type DefaultsServer struct {
+	Name string `default:"web"`
+	Port int    `default:"80"`
+}
+
+type Defaults struct {
+	DefaultsBase
+	Host     string        `default:"localhost"`
+	Port     uint16        `default:"8080"`
+	Debug    bool          `default:"true"`
+	Timeout  time.Duration `default:"30"`
+	Tags     []string      `default:"a,b"`
+	Limits   map[string]int
+	Retries  *int `default:"3"`
+	TLS      *DefaultsTLS
+	Servers  []DefaultsServer
+	Primary  DefaultsServer
+	NoTag    int
+	internal int `default:"1"`
+}
+
+func TestApplyDefaults(t *testing.T) {
+	var data = &Defaults{
+		Host:    "example.com",
+		TLS:     &DefaultsTLS{},
+		Servers: []DefaultsServer{{Port: 81}, {Name: "db"}},
+	}
+	if err := ApplyDefaults(data); err != nil {
+		t.Fatal(err)
+	}
+	var three = 3
+	var expect = &Defaults{
+		DefaultsBase: DefaultsBase{Region: "eu"},
+		Host:         "example.com",
+		Port:         8080,
+		Debug:        true,
+		Timeout:      30,
+		Tags:         []string{"a", "b"},
+		Retries:      &three,
+		TLS:          &DefaultsTLS{Cert: "cert.pem"},
+		Servers:      []DefaultsServer{{Name: "web", Port: 81}, {Name: "db", Port: 80}},
+		Primary:      DefaultsServer{Name: "web", Port: 80},
+	}
+	if !reflect.DeepEqual(data, expect) {
+		t.Fatalf("ApplyDefaults failed: want %+v, got %+v", expect, data)
+	}
+	if err := ApplyDefaults(&Defaults{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ApplyDefaults(Defaults{}); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal("ApplyDefaults failed.", err)
+	}
+	if err := ApplyDefaults(new(int)); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal("ApplyDefaults failed.", err)
+	}
+	var invalid struct {
+		Servers []struct {
+			Port int `default:"eighty"`
+		}
+	}
+	invalid.Servers = make([]struct {
+		Port int `default:"eighty"`
+	}, 2)
+	if err := ApplyDefaults(&invalid); err == nil || !strings.HasPrefix(err.Error(), "Servers[0].Port: ") {
+		t.Fatal("ApplyDefaults failed.", err)
+	}
+}
+
+func TestDecoderApplyDefaults(t *testing.T) {
+	var d = &Decoder{ApplyDefaults: true}
+	var data Defaults
+	if err := d.DecodeString("{Port=0, Host=example.com, Region=us}", &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Port != 0 || data.Host != "example.com" || data.Region != "us" ||
+		!data.Debug || data.Timeout != 30 || data.Primary.Name != "web" || data.TLS != nil {
+		t.Fatalf("DecodeString failed: %+v", data)
+	}
+	var servers []DefaultsServer
+	if err := d.DecodeString("{Port=1}", &servers); err != nil || len(servers) != 1 || servers[0].Name != "web" {
+		t.Fatal("DecodeString failed.", err)
+	}
+	var server *DefaultsServer
+	if err := d.DecodeString("{Port=1}", &server); err != nil || server.Name != "web" || server.Port != 1 {
+		t.Fatal("DecodeString failed.", err)
+	}
+	data = Defaults{}
+	if err := StringToInterface("{Port=1}", &data); err != nil || data.Host != "" {
+		t.Fatal("StringToInterface applied defaults.", err)
+	}
+}
+
+type DefaultsNode struct {
+	Name     string         `default:"node"`
+	Next     *DefaultsNode  `default:"{}"`
+	Children []DefaultsNode `default:"{}"`
+	Items    map[string]int `default:"a=1"`
+	Self     *DefaultsNode
+}
+
+func TestApplyDefaultsRecursive(t *testing.T) {
+	var node = &DefaultsNode{}
+	node.Self = node
+	if err := ApplyDefaults(node); err != nil {
+		t.Fatal(err)
+	}
+	if node.Name != "node" || node.Next == nil || node.Next.Name != "" || node.Next.Next != nil ||
+		len(node.Children) != 1 || node.Children[0].Name != "" || node.Items["a"] != 1 {
+		t.Fatalf("ApplyDefaults failed: %+v", node)
+	}
+	var d = &Decoder{ApplyDefaults: true}
+	var decoded DefaultsNode
+	if err := d.DecodeString("{Name=root}", &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Name != "root" || decoded.Next == nil || decoded.Next.Name != "" || decoded.Next.Next != nil {
+		t.Fatalf("DecodeString failed: %+v", decoded)
+	}
+}
@@ -0,0 +1,376 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Op defines a Change operation.
+type Op int
+
+const (
+	// AddOp marks an addition of a value.
+	AddOp Op = iota
+	// RemoveOp marks a removal of a value.
+	RemoveOp
+	// ReplaceOp marks a replacement of a value.
+	ReplaceOp
+)
+
+// String implements stringer on Op.
+func (o Op) String() (s string) {
+	switch o {
+	case AddOp:
+		s = "add"
+	case RemoveOp:
+		s = "remove"
+	case ReplaceOp:
+		s = "replace"
+	}
+	return
+}
+
+// Change describes a single difference between two Go values.
+type Change struct {
+	// Op is the change operation.
+	Op Op
+	// Path is the path to the changed value in the syntax understood by Find.
+	Path string
+	// Old is the string representation of the value before the change.
+	// It is empty for AddOp and for removals of compound values.
+	Old string
+	// New is the string representation of the value after the change.
+	// It is empty for RemoveOp and for additions of compound values.
+	New string
+}
+
+// String implements stringer on Change.
+func (c Change) String() string {
+	switch c.Op {
+	case AddOp:
+		return fmt.Sprintf("%s %s %s", c.Op, c.Path, strconv.Quote(c.New))
+	case RemoveOp:
+		return fmt.Sprintf("%s %s %s", c.Op, c.Path, strconv.Quote(c.Old))
+	}
+	return fmt.Sprintf("%s %s %s %s", c.Op, c.Path, strconv.Quote(c.Old), strconv.Quote(c.New))
+}
+
+// Diff returns changes that transform a into b which must be values of the
+// same type, as a list in the order they should be applied by Apply.
+//
+// Simple values are compared by their string representation as returned by
+// ValueToString. Struct fields are compared by name, Array and Slice elements
+// by index and Map elements by key. Pointers and interfaces are compared by
+// the values they point to. A pointer, Slice or Map that references a value
+// containing it, i.e. a cycle, is compared once.
+//
+// A Map or Slice element or a value pointed to that exists only in b is
+// described by an AddOp at its path followed, if it is a compound value, by
+// an AddOp for each simple value it contains and each non-nil compound value
+// it contains that contains no simple values, as Flatten returns them. A
+// value that exists only in a is described by the opposite, in reverse
+// order. Slice elements are removed starting from the last one. A nil Slice
+// or Map in a that is not nil in b is described as a value that exists only
+// in b. A Slice or Map in a that is nil in b is described by removals of its
+// elements followed by a ReplaceOp with an empty New.
+//
+// Apply cannot set a nil interface to a value or change the dynamic type of
+// the value in an interface as the type is not described by a Change. Diff
+// returns an error wrapping ErrUnsupportedValue if a and b differ so.
+func Diff(a, b interface{}) ([]Change, error) {
+	if a == nil || b == nil {
+		return nil, ErrInvalidArgument
+	}
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return nil, fmt.Errorf("%w: type mismatch: %T, %T", ErrInvalidArgument, a, b)
+	}
+	var changes []Change
+	if err := diff("", reflect.ValueOf(a), reflect.ValueOf(b), &changes, make(map[[2]ptrKey]bool)); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// diff appends changes between values a and b at path to changes. Seen
+// holds pairs of pointers, Slices and Maps being compared.
+func diff(path string, a, b reflect.Value, changes *[]Change, seen map[[2]ptrKey]bool) error {
+	if ka, ok := pointerKey(a); ok {
+		if kb, ok := pointerKey(b); ok {
+			var key = [2]ptrKey{ka, kb}
+			if seen[key] {
+				return nil
+			}
+			seen[key] = true
+			defer delete(seen, key)
+		}
+	}
+	if a.Kind() == reflect.Ptr || a.Kind() == reflect.Interface {
+		switch {
+		case a.IsNil() && b.IsNil():
+			return nil
+		case a.IsNil():
+			return added(path, b, changes)
+		case b.IsNil():
+			return removed(path, a, changes)
+		case a.Elem().Type() != b.Elem().Type():
+			return fmt.Errorf("%w: %s: dynamic type changes from %s to %s", ErrUnsupportedValue, path, a.Elem().Type(), b.Elem().Type())
+		}
+		return diff(path, a.Elem(), b.Elem(), changes, seen)
+	}
+	if isLeaf(a) {
+		var olds, err = valueToString(a)
+		if err != nil {
+			return err
+		}
+		var news string
+		if news, err = valueToString(b); err != nil {
+			return err
+		}
+		if olds != news {
+			*changes = append(*changes, Change{Op: ReplaceOp, Path: path, Old: olds, New: news})
+		}
+		return nil
+	}
+	if isNilContainer(a) != isNilContainer(b) {
+		if isNilContainer(a) {
+			return added(path, b, changes)
+		}
+		if err := removed(path, a, changes); err != nil {
+			return err
+		}
+		// Replace the removal of a itself so that the container at path
+		// keeps its element.
+		(*changes)[len(*changes)-1] = Change{Op: ReplaceOp, Path: path}
+		return nil
+	}
+	var err error
+	switch a.Kind() {
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			var field = a.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			if err = diff(joinName(path, field.Name), a.Field(i), b.Field(i), changes, seen); err != nil {
+				return err
+			}
+		}
+	case reflect.Array, reflect.Slice:
+		var i int
+		for i = 0; i < a.Len() && i < b.Len(); i++ {
+			if err = diff(joinKey(path, strconv.Itoa(i)), a.Index(i), b.Index(i), changes, seen); err != nil {
+				return err
+			}
+		}
+		for j := a.Len() - 1; j >= i; j-- {
+			if err = removed(joinKey(path, strconv.Itoa(j)), a.Index(j), changes); err != nil {
+				return err
+			}
+		}
+		for ; i < b.Len(); i++ {
+			if err = added(joinKey(path, strconv.Itoa(i)), b.Index(i), changes); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		var key string
+		for _, mapkey := range sortedKeys(a) {
+			if key, err = valueToString(mapkey); err != nil {
+				return err
+			}
+			if bv := b.MapIndex(mapkey); bv.IsValid() {
+				err = diff(joinKey(path, quoteKey(key)), a.MapIndex(mapkey), bv, changes, seen)
+			} else {
+				err = removed(joinKey(path, quoteKey(key)), a.MapIndex(mapkey), changes)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		for _, mapkey := range sortedKeys(b) {
+			if a.MapIndex(mapkey).IsValid() {
+				continue
+			}
+			if key, err = valueToString(mapkey); err != nil {
+				return err
+			}
+			if err = added(joinKey(path, quoteKey(key)), b.MapIndex(mapkey), changes); err != nil {
+				return err
+			}
+		}
+	default:
+		return ErrUnsupportedValue
+	}
+	return nil
+}
+
+// added appends changes that add value v at path to changes. Returns an
+// error if v is or contains a non-nil interface which Apply cannot add.
+func added(path string, v reflect.Value, changes *[]Change) error {
+	var check = func(path string, value reflect.Value) error {
+		if value.Kind() == reflect.Interface && !value.IsNil() {
+			return fmt.Errorf("%w: %s: interface value added", ErrUnsupportedValue, path)
+		}
+		return nil
+	}
+	if err := check(path, v); err != nil {
+		return err
+	}
+	if err := walk(path, v, 0, -1, check); err != nil {
+		return err
+	}
+	return contents(path, v, changes)
+}
+
+// removed appends changes that remove value v at path to changes.
+func removed(path string, v reflect.Value, changes *[]Change) error {
+	var leaves []Change
+	if err := contents(path, v, &leaves); err != nil {
+		return err
+	}
+	for i := len(leaves) - 1; i >= 0; i-- {
+		*changes = append(*changes, Change{Op: RemoveOp, Path: leaves[i].Path, Old: leaves[i].New})
+	}
+	return nil
+}
+
+// contents appends an AddOp for value v at path and, if v is a compound
+// value, for its contents as returned by flatten, to changes.
+func contents(path string, v reflect.Value, changes *[]Change) error {
+	if leaf := indirect(v); leaf.IsValid() && isLeaf(leaf) {
+		var s, err = valueToString(leaf)
+		if err != nil {
+			return err
+		}
+		*changes = append(*changes, Change{Op: AddOp, Path: path, New: s})
+		return nil
+	}
+	*changes = append(*changes, Change{Op: AddOp, Path: path})
+	return flatten(path, v, func(path, s string) {
+		*changes = append(*changes, Change{Op: AddOp, Path: path, New: s})
+	})
+}
+
+// Reverse returns the inverse of each of specified changes in reverse order.
+//
+// Applying the result after changes does not always restore the original
+// value as changes do not fully describe it. For example, the inverse of a
+// ReplaceOp with an empty New that sets a Slice to nil sets it to nil again
+// instead of making it empty and the inverse of a RemoveOp of a Slice
+// element that had elements after it overwrites the element at its index
+// instead of inserting it.
+func Reverse(changes []Change) []Change {
+	var result = make([]Change, 0, len(changes))
+	for i := len(changes) - 1; i >= 0; i-- {
+		var c = Change{Path: changes[i].Path, Old: changes[i].New, New: changes[i].Old}
+		switch changes[i].Op {
+		case AddOp:
+			c.Op = RemoveOp
+		case RemoveOp:
+			c.Op = AddOp
+		case ReplaceOp:
+			c.Op = ReplaceOp
+		}
+		result = append(result, c)
+	}
+	return result
+}
+
+// Apply applies changes to root which must be a pointer.
+//
+// ReplaceOp sets the value at path as Set does, or, if New is empty and the
+// value is a compound value, sets it to its zero value so that Slices and
+// Maps are set to nil. AddOp does the same but also grows Slices as
+// needed and, for compound values with an empty New, only creates the value,
+// making nil Slices and Maps empty. RemoveOp deletes the Map or Slice
+// element at path, or sets the value at path to zero, setting pointers to
+// nil. Changes within non-nil interfaces are applied to copies of the values
+// they hold which are then stored back into them.
+//
+// Unlike Set, Apply does not validate the values it sets. Apply stops at the
+// first change that fails and returns its error. Changes before it remain
+// applied.
+func Apply(changes []Change, root interface{}) error {
+	if root == nil {
+		return ErrInvalidArgument
+	}
+	var segs []segment
+	var err error
+	for _, change := range changes {
+		if segs, err = segments(change.Path); err != nil {
+			return fmt.Errorf("%s: %w", change.Path, err)
+		}
+		switch change.Op {
+		case AddOp, ReplaceOp:
+			var op, value = change.Op, change.New
+			err = modify(reflect.ValueOf(root), segs, op == AddOp, func(v reflect.Value) error {
+				if !v.CanSet() {
+					return ErrUnaddressableValue
+				}
+				if value == "" && !isLeaf(v) {
+					switch {
+					case op == ReplaceOp:
+						v.Set(reflect.Zero(v.Type()))
+					case v.Kind() == reflect.Slice && v.IsNil():
+						v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+					case v.Kind() == reflect.Map && v.IsNil():
+						v.Set(reflect.MakeMap(v.Type()))
+					}
+					return nil
+				}
+				return StringToValue(value, v)
+			})
+		case RemoveOp:
+			var last = segs[len(segs)-1]
+			err = modify(reflect.ValueOf(root), segs[:len(segs)-1], false, func(v reflect.Value) error {
+				return remove(v, last)
+			})
+		default:
+			err = ErrInvalidArgument
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", change.Path, err)
+		}
+	}
+	return nil
+}
+
+// remove removes the element specified by seg from container v. Map and
+// Slice elements are deleted, other values are set to zero.
+func remove(v reflect.Value, seg segment) error {
+	var elem reflect.Value
+	if seg.token == NameToken {
+		var err error
+		if elem, err = fieldBySegment(v, seg, false); err != nil {
+			return err
+		}
+	} else if seg.token == KeyToken {
+		switch v.Kind() {
+		case reflect.Map:
+			var key, err = mapKey(v, seg.key)
+			if err != nil {
+				return err
+			}
+			v.SetMapIndex(key, reflect.Value{})
+			return nil
+		case reflect.Slice:
+			return removeAt(v, seg.key, nil)
+		}
+		var err error
+		if elem, err = valueByKey(v, seg.key); err != nil {
+			return err
+		}
+	} else {
+		return errWildcard
+	}
+	if !elem.CanSet() {
+		return ErrUnaddressableValue
+	}
+	elem.Set(reflect.Zero(elem.Type()))
+	return nil
+}
@@ -0,0 +1,198 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type DiffChild struct {
+	Name string
+	Tags []string
+}
+
+type DiffRoot struct {
+	Port   int
+	Child  *DiffChild
+	Hosts  []string
+	Map    map[string]DiffChild
+	Labels map[string]string
+}
+
+func getDiffData() (a, b *DiffRoot) {
+	a = &DiffRoot{
+		Port:  80,
+		Hosts: []string{"a", "b", "c"},
+		Map: map[string]DiffChild{
+			"one": {Name: "one", Tags: []string{"x"}},
+			"two": {Name: "two", Tags: []string{"y", "z"}},
+		},
+		Labels: map[string]string{"env": "prod", "app": "web"},
+	}
+	b = &DiffRoot{
+		Port:  8080,
+		Child: &DiffChild{Name: "child", Tags: []string{"t"}},
+		Hosts: []string{"a", "d"},
+		Map: map[string]DiffChild{
+			"one":   {Name: "one", Tags: []string{"x", "w"}},
+			"three": {Name: "three"},
+		},
+		Labels: map[string]string{"env": "dev", "team": "ops"},
+	}
+	return
+}
+
+func TestDiff(t *testing.T) {
+	var a, b = getDiffData()
+	var changes, err = Diff(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expect = []Change{
+		{Op: ReplaceOp, Path: "Port", Old: "80", New: "8080"},
+		{Op: AddOp, Path: "Child"},
+		{Op: AddOp, Path: "Child.Name", New: "child"},
+		{Op: AddOp, Path: "Child.Tags[0]", New: "t"},
+		{Op: ReplaceOp, Path: "Hosts[1]", Old: "b", New: "d"},
+		{Op: RemoveOp, Path: "Hosts[2]", Old: "c"},
+		{Op: AddOp, Path: "Map[one].Tags[1]", New: "w"},
+		{Op: RemoveOp, Path: "Map[two].Tags[1]", Old: "z"},
+		{Op: RemoveOp, Path: "Map[two].Tags[0]", Old: "y"},
+		{Op: RemoveOp, Path: "Map[two].Name", Old: "two"},
+		{Op: RemoveOp, Path: "Map[two]"},
+		{Op: AddOp, Path: "Map[three]"},
+		{Op: AddOp, Path: "Map[three].Name", New: "three"},
+		{Op: RemoveOp, Path: "Labels[app]", Old: "web"},
+		{Op: ReplaceOp, Path: "Labels[env]", Old: "prod", New: "dev"},
+		{Op: AddOp, Path: "Labels[team]", New: "ops"},
+	}
+	if !reflect.DeepEqual(changes, expect) {
+		t.Fatalf("Diff failed: want %v, got %v", expect, changes)
+	}
+	if _, err = Diff(a, *b); err == nil {
+		t.Fatal("Diff failed.")
+	}
+}
+
+func TestApply(t *testing.T) {
+	var a, b = getDiffData()
+	var changes, err = Diff(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = Apply(changes, a); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("Apply failed: want %#v, got %#v", b, a)
+	}
+	var original, _ = getDiffData()
+	if err = Apply(Reverse(changes), a); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, original) {
+		t.Fatalf("Apply(Reverse) failed: want %#v, got %#v", original, a)
+	}
+	if changes, err = Diff(a, original); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("Diff failed: want no changes, got %v", changes)
+	}
+}
+
+type DiffNode struct {
+	Name string
+	Next *DiffNode
+}
+
+func TestDiffCycle(t *testing.T) {
+	var a = &DiffNode{Name: "a"}
+	a.Next = a
+	var b = &DiffNode{Name: "b"}
+	b.Next = b
+	var changes, err = Diff(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expect = []Change{{Op: ReplaceOp, Path: "Name", Old: "a", New: "b"}}
+	if !reflect.DeepEqual(changes, expect) {
+		t.Fatalf("Diff failed: want %v, got %v", expect, changes)
+	}
+	var list = []interface{}{1, nil}
+	list[1] = list
+	if _, err = Diff(list, list); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type DiffEmpty struct {
+	Tags   []string
+	Labels map[string]string
+	Nested [][]int
+	Set    map[string]struct{}
+}
+
+func TestApplyEmpty(t *testing.T) {
+	var a = &DiffEmpty{Tags: []string{"a"}, Nested: [][]int{{1}, nil}}
+	var b = &DiffEmpty{
+		Labels: map[string]string{},
+		Nested: [][]int{nil, {}, {}},
+		Set:    map[string]struct{}{"x": {}},
+	}
+	var changes, err = Diff(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = Apply(changes, a); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("Apply failed: want %#v, got %#v", b, a)
+	}
+	if changes, err = Diff(a, b); err != nil || len(changes) != 0 {
+		t.Fatalf("Diff failed: want no changes, got %v", changes)
+	}
+}
+
+type DiffInterface struct {
+	Value interface{}
+	Map   map[string]interface{}
+}
+
+func TestDiffInterface(t *testing.T) {
+	var a = &DiffInterface{Value: DiffChild{Name: "a"}, Map: map[string]interface{}{"n": 1}}
+	var b = &DiffInterface{Value: DiffChild{Name: "b"}, Map: map[string]interface{}{"n": 2}}
+	var changes, err = Diff(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = Apply(changes, a); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("Apply failed: want %#v, got %#v", b, a)
+	}
+	b.Value = "b"
+	if _, err = Diff(a, b); !errors.Is(err, ErrUnsupportedValue) {
+		t.Fatal("Diff failed.", err)
+	}
+	b.Value, b.Map["m"] = a.Value, 3
+	if _, err = Diff(a, b); !errors.Is(err, ErrUnsupportedValue) {
+		t.Fatal("Diff failed.", err)
+	}
+	a.Value, b.Map = nil, map[string]interface{}{"n": 2}
+	if changes, err = Diff(b, a); err != nil {
+		t.Fatal(err)
+	}
+	if err = Apply(changes, b); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("Apply failed: want %#v, got %#v", a, b)
+	}
+}
@@ -0,0 +1,393 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Integer is a constraint that permits any integer type.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// EnumOptions are the options of an enum registered with RegisterEnum.
+type EnumOptions struct {
+	// CaseInsensitive makes names match regardless of case.
+	CaseInsensitive bool
+}
+
+// FlagOptions are the options of flags registered with RegisterFlags.
+type FlagOptions struct {
+	// CaseInsensitive makes names match regardless of case.
+	CaseInsensitive bool
+	// Separator separates names of flags. If empty "|" is used. It may not
+	// contain characters reserved by StringToValue for compound values; a
+	// comma, an equals sign or braces.
+	Separator string
+}
+
+// EnumError is returned when a string is neither a name of a value of an
+// enum type nor a number. It wraps ErrInvalidValue.
+type EnumError struct {
+	// Type is the enum type.
+	Type reflect.Type
+	// Name is the name as specified.
+	Name string
+	// Allowed are the names of values of Type, in ascending value order.
+	Allowed []string
+}
+
+// Error implements error on EnumError.
+func (e *EnumError) Error() string {
+	return fmt.Sprintf("%s: unknown %s name %q, allowed: %s",
+		ErrInvalidValue, e.Type, e.Name, strings.Join(e.Allowed, ", "))
+}
+
+// Unwrap returns ErrInvalidValue.
+func (e *EnumError) Unwrap() error { return ErrInvalidValue }
+
+// RegisterEnum registers names of values of integer type T. Values of type T
+// are then converted from their names, or numbers, and converted to their
+// names, or numbers if they have no name, everywhere in this package. If
+// several names have the same value the first one in lexical order is
+// emitted.
+//
+// Registering a type again replaces its names. Names may not be empty or
+// numbers.
+//
+// Integer types that are not registered but implement fmt.Stringer and have
+// a Values method that returns a slice of all values of the type, e.g.
+//
+//	func (l Level) Values() []Level { return []Level{Debug, Info, Warn} }
+//
+// are registered automatically with names returned by String and default
+// options on first use.
+func RegisterEnum[T Integer](values map[string]T, options EnumOptions) error {
+	var names = make(map[string]uint64, len(values))
+	for name, value := range values {
+		names[name] = integerBits(reflect.ValueOf(value))
+	}
+	var t = reflect.TypeOf((*T)(nil)).Elem()
+	var e, err = newEnum(t, names, options.CaseInsensitive, "")
+	if err != nil {
+		return err
+	}
+	enums.Store(t, e)
+	return nil
+}
+
+// MustRegisterEnum is like RegisterEnum but panics on error.
+func MustRegisterEnum[T Integer](values map[string]T, options EnumOptions) {
+	if err := RegisterEnum(values, options); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterFlags registers names of bit flags of integer type T. Values of
+// type T are then converted from names and numbers delimited by a separator,
+// e.g. "read|write|exec", to the bitwise OR of their values and converted to
+// names of the flags they contain, in ascending value order, followed by a
+// hexadecimal number of the remaining bits, if any, e.g. "read|exec|0x10".
+// A value with a name is converted to that name and zero is converted to
+// "0" if it has no name. Numbers may be decimal or prefixed with "0x", "0o"
+// or "0b".
+//
+// Registering a type again replaces its names. Names may not be empty,
+// numbers or contain the separator.
+func RegisterFlags[T Integer](values map[string]T, options FlagOptions) error {
+	if options.Separator == "" {
+		options.Separator = "|"
+	}
+	if strings.ContainsAny(options.Separator, ",={}") {
+		return fmt.Errorf("%w: reserved flag separator %q", ErrInvalidArgument, options.Separator)
+	}
+	var names = make(map[string]uint64, len(values))
+	for name, value := range values {
+		if strings.Contains(name, options.Separator) {
+			return fmt.Errorf("%w: invalid flag name %q", ErrInvalidArgument, name)
+		}
+		names[name] = integerBits(reflect.ValueOf(value))
+	}
+	var t = reflect.TypeOf((*T)(nil)).Elem()
+	var e, err = newEnum(t, names, options.CaseInsensitive, options.Separator)
+	if err != nil {
+		return err
+	}
+	enums.Store(t, e)
+	return nil
+}
+
+// MustRegisterFlags is like RegisterFlags but panics on error.
+func MustRegisterFlags[T Integer](values map[string]T, options FlagOptions) {
+	if err := RegisterFlags(values, options); err != nil {
+		panic(err)
+	}
+}
+
+// enum holds the names of values or flags of an integer type. Values are
+// held as bits of an uint64; signed values are converted.
+type enum struct {
+	typ     reflect.Type
+	fold    bool
+	sep     string // flag separator, empty if not flags
+	names   map[string]uint64
+	values  map[uint64]string
+	allowed []string
+}
+
+// enums caches an *enum per named integer type. It holds a nil *enum for
+// types that are not enums.
+var enums sync.Map // reflect.Type -> *enum
+
+// stringerType is the type of fmt.Stringer.
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+// newEnum returns a new *enum of type t with names matched case-insensitively
+// if fold is true. It holds flags delimited by sep if sep is not empty.
+func newEnum(t reflect.Type, names map[string]uint64, fold bool, sep string) (*enum, error) {
+	var e = &enum{
+		typ:    t,
+		fold:   fold,
+		sep:    sep,
+		names:  make(map[string]uint64, len(names)),
+		values: make(map[uint64]string, len(names)),
+	}
+	var sorted = make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	for _, name := range sorted {
+		if name == "" || isNumber(name) {
+			return nil, fmt.Errorf("%w: invalid %s name %q", ErrInvalidArgument, t, name)
+		}
+		var key = e.key(name)
+		if _, exists := e.names[key]; exists {
+			return nil, fmt.Errorf("%w: duplicate %s name %q", ErrInvalidArgument, t, name)
+		}
+		var value = names[name]
+		e.names[key] = value
+		if _, exists := e.values[value]; !exists {
+			e.values[value] = name
+		}
+	}
+	e.allowed = sorted
+	sort.SliceStable(e.allowed, func(i, j int) bool {
+		return e.less(names[e.allowed[i]], names[e.allowed[j]])
+	})
+	return e, nil
+}
+
+// enumFor returns the *enum of type t or nil if t is not an enum.
+func enumFor(t reflect.Type) *enum {
+	if t.PkgPath() == "" {
+		return nil
+	}
+	if e, ok := enums.Load(t); ok {
+		return e.(*enum)
+	}
+	var e, _ = enums.LoadOrStore(t, detectEnum(t))
+	return e.(*enum)
+}
+
+// detectEnum returns an *enum of integer type t built from its Values and
+// String methods or nil if t does not have them or they fail.
+func detectEnum(t reflect.Type) (e *enum) {
+	defer func() {
+		if recover() != nil {
+			e = nil
+		}
+	}()
+	var m, ok = t.MethodByName("Values")
+	if !ok || !t.Implements(stringerType) {
+		return nil
+	}
+	if m.Type.NumIn() != 1 || m.Type.NumOut() != 1 || m.Type.Out(0) != reflect.SliceOf(t) {
+		return nil
+	}
+	var values = reflect.Zero(t).Method(m.Index).Call(nil)[0]
+	var names = make(map[string]uint64, values.Len())
+	for i := 0; i < values.Len(); i++ {
+		var v = values.Index(i)
+		names[v.Interface().(fmt.Stringer).String()] = integerBits(v)
+	}
+	var err error
+	if e, err = newEnum(t, names, false, ""); err != nil {
+		return nil
+	}
+	return e
+}
+
+// key returns the key of name in e.names.
+func (e *enum) key(name string) string {
+	if e.fold {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// signed returns true if the type of e is a signed integer.
+func (e *enum) signed() bool {
+	switch e.typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+// less compares values of e. Flags are compared as unsigned bits.
+func (e *enum) less(a, b uint64) bool {
+	if e.sep != "" {
+		return a&e.mask() < b&e.mask()
+	}
+	if e.signed() {
+		return int64(a) < int64(b)
+	}
+	return a < b
+}
+
+// mask returns a mask of the bits of the type of e.
+func (e *enum) mask() uint64 {
+	if size := e.typ.Bits(); size < 64 {
+		return 1<<size - 1
+	}
+	return ^uint64(0)
+}
+
+// decode converts a name or a number in to integer out.
+func (e *enum) decode(in string, out reflect.Value) error {
+	if value, ok := e.names[e.key(in)]; ok {
+		setIntegerBits(out, value)
+		return nil
+	}
+	if e.sep != "" {
+		return e.decodeFlags(in, out)
+	}
+	if !isNumber(in) {
+		return &EnumError{Type: e.typ, Name: in, Allowed: e.allowed}
+	}
+	if e.signed() {
+		return parseInt(in, out)
+	}
+	return parseUint(in, out)
+}
+
+// decodeFlags converts names and numbers of flags in to integer out.
+func (e *enum) decodeFlags(in string, out reflect.Value) error {
+	var bits uint64
+	for _, part := range strings.Split(in, e.sep) {
+		if part = strings.TrimSpace(part); part == "" && in == "" {
+			break
+		}
+		if value, ok := e.names[e.key(part)]; ok {
+			bits |= value
+			continue
+		}
+		if !isNumber(part) {
+			return &EnumError{Type: e.typ, Name: part, Allowed: e.allowed}
+		}
+		var value, err = e.parseBits(part)
+		if err != nil {
+			return err
+		}
+		bits |= value
+	}
+	setIntegerBits(out, bits)
+	return nil
+}
+
+// parseBits parses a number that fits in the type of e to its bits.
+func (e *enum) parseBits(s string) (uint64, error) {
+	var size = e.typ.Bits()
+	if !e.signed() {
+		var n, err = strconv.ParseUint(s, 0, size)
+		return n, err
+	}
+	if n, err := strconv.ParseInt(s, 0, size); err == nil {
+		return uint64(n), nil
+	}
+	// Accept bits of negative values as unsigned numbers, e.g. 0xff for an
+	// int8.
+	var n, err = strconv.ParseUint(s, 0, size)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(int64(n<<(64-size)) >> (64 - size)), nil
+}
+
+// format converts integer in to its name or a number if it has none.
+func (e *enum) format(in reflect.Value) string {
+	if name, ok := e.values[integerBits(in)]; ok {
+		return name
+	}
+	if e.sep != "" {
+		return e.formatFlags(in)
+	}
+	if e.signed() {
+		return strconv.FormatInt(in.Int(), 10)
+	}
+	return strconv.FormatUint(in.Uint(), 10)
+}
+
+// integerBits returns integer v as bits of an uint64.
+func integerBits(v reflect.Value) uint64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(v.Int())
+	}
+	return v.Uint()
+}
+
+// setIntegerBits sets integer v to bits.
+func setIntegerBits(v reflect.Value, bits uint64) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(int64(bits))
+	default:
+		v.SetUint(bits)
+	}
+}
+
+// isNumber returns true if s starts like a decimal number, optionally
+// signed.
+func isNumber(s string) bool {
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
+	}
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
+
+// formatFlags converts integer in to names of flags it contains followed by
+// a hexadecimal number of the remaining bits.
+func (e *enum) formatFlags(in reflect.Value) string {
+	var mask = e.mask()
+	var bits = integerBits(in) & mask
+	if bits == 0 {
+		return "0"
+	}
+	var parts []string
+	for _, name := range e.allowed {
+		var value = e.names[e.key(name)]
+		if e.values[value] != name {
+			continue
+		}
+		if value &= mask; value == 0 || bits&value != value {
+			continue
+		}
+		parts = append(parts, name)
+		bits &^= value
+	}
+	if bits != 0 {
+		parts = append(parts, "0x"+strconv.FormatUint(bits, 16))
+	}
+	return strings.Join(parts, e.sep)
+}
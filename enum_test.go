@@ -0,0 +1,215 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type Level int
+
+const (
+	LevelDebug Level = iota - 1
+	LevelInfo
+	LevelWarn
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	}
+	return "unknown"
+}
+
+func (l Level) Values() []Level { return []Level{LevelDebug, LevelInfo, LevelWarn} }
+
+type Color uint8
+
+type Shape int
+
+func (s Shape) String() string { return "shape" }
+
+type Logging struct {
+	Level  Level
+	Color  Color
+	Levels map[Level]Color
+}
+
+func init() {
+	MustRegisterEnum(map[string]Color{"Red": 1, "Green": 2, "Blue": 4, "Azure": 4}, EnumOptions{CaseInsensitive: true})
+}
+
+func TestEnum(t *testing.T) {
+	var data Logging
+	if err := StringToInterface("{Level=warn,Color=RED}", &data); err != nil {
+		t.Fatal(err)
+	}
+	if err := StringToInterface("debug=green,info=5", &data.Levels); err != nil {
+		t.Fatal(err)
+	}
+	var expect = Logging{
+		Level:  LevelWarn,
+		Color:  1,
+		Levels: map[Level]Color{LevelDebug: 2, LevelInfo: 5},
+	}
+	if !reflect.DeepEqual(data, expect) {
+		t.Fatal("StringToInterface failed.")
+	}
+	var s, err = InterfaceToString(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "{Level=warn,Color=Red,Levels=debug=Green,info=5}" {
+		t.Fatalf("InterfaceToString failed: %s", s)
+	}
+	if s, _ = InterfaceToString(Color(4)); s != "Azure" {
+		t.Fatalf("InterfaceToString failed: %s", s)
+	}
+	if err = Set("Level", "-1", &data); err != nil || data.Level != LevelDebug {
+		t.Fatal("Set failed.")
+	}
+	if err = Set("Level", "Warn", &data); err == nil {
+		t.Fatal("Set failed.")
+	}
+	var shape Shape
+	if err = StringToInterface("3", &shape); err != nil || shape != 3 {
+		t.Fatal("StringToInterface failed.")
+	}
+	if s, _ = InterfaceToString(shape); s != "3" {
+		t.Fatal("InterfaceToString failed.")
+	}
+}
+
+func TestEnumError(t *testing.T) {
+	var level Level
+	var err = StringToInterface("trace", &level)
+	var eerr *EnumError
+	if !errors.As(err, &eerr) || !errors.Is(err, ErrInvalidValue) {
+		t.Fatal("StringToInterface failed.")
+	}
+	if eerr.Name != "trace" || !reflect.DeepEqual(eerr.Allowed, []string{"debug", "info", "warn"}) {
+		t.Fatal("StringToInterface failed.")
+	}
+	if err.Error() != `strconvex: invalid value: unknown strconvex.Level name "trace", allowed: debug, info, warn` {
+		t.Fatal(err)
+	}
+	var color Color
+	if err = StringToInterface("purple", &color); !errors.As(err, &eerr) ||
+		!reflect.DeepEqual(eerr.Allowed, []string{"Red", "Green", "Azure", "Blue"}) {
+		t.Fatal("StringToInterface failed.")
+	}
+}
+
+func TestRegisterEnum(t *testing.T) {
+	type Mode int
+	if err := RegisterEnum(map[string]Mode{"": 1}, EnumOptions{}); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal("RegisterEnum failed.")
+	}
+	if err := RegisterEnum(map[string]Mode{"1st": 1}, EnumOptions{}); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal("RegisterEnum failed.")
+	}
+	if err := RegisterEnum(map[string]Mode{"on": 1, "ON": 2}, EnumOptions{CaseInsensitive: true}); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal("RegisterEnum failed.")
+	}
+	if err := RegisterEnum(map[string]Mode{"on": 1, "ON": 2}, EnumOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	var mode Mode
+	if err := StringToInterface("ON", &mode); err != nil || mode != 2 {
+		t.Fatal("StringToInterface failed.")
+	}
+	MustRegisterEnum(map[string]Mode{"off": 0}, EnumOptions{})
+	if err := StringToInterface("on", &mode); err == nil {
+		t.Fatal("StringToInterface failed.")
+	}
+}
+
+type Perm uint32
+
+const (
+	PermRead Perm = 1 << iota
+	PermWrite
+	PermExec
+)
+
+type Feature int8
+
+func init() {
+	MustRegisterFlags(map[string]Perm{
+		"read":  PermRead,
+		"write": PermWrite,
+		"exec":  PermExec,
+		"rw":    PermRead | PermWrite,
+	}, FlagOptions{})
+	MustRegisterFlags(map[string]Feature{"a": 1, "b": 2, "last": -128}, FlagOptions{Separator: "+", CaseInsensitive: true})
+}
+
+func TestFlags(t *testing.T) {
+	var tests = []struct {
+		In     string
+		Expect Perm
+		Out    string
+	}{
+		{"", 0, "0"},
+		{"read", PermRead, "read"},
+		{"read|write", PermRead | PermWrite, "rw"},
+		{"rw", PermRead | PermWrite, "rw"},
+		{"read | exec", PermRead | PermExec, "read|exec"},
+		{"write|exec|0x10", PermWrite | PermExec | 16, "write|exec|0x10"},
+		{"7", 7, "read|write|exec"},
+		{"0x30|read", 0x31, "read|0x30"},
+	}
+	for _, test := range tests {
+		var perm Perm
+		if err := StringToInterface(test.In, &perm); err != nil {
+			t.Fatalf("StringToInterface failed: %q: %v", test.In, err)
+		}
+		if perm != test.Expect {
+			t.Fatalf("StringToInterface failed: %q: %d", test.In, perm)
+		}
+		if s, err := InterfaceToString(perm); err != nil || s != test.Out {
+			t.Fatalf("InterfaceToString failed: %q: %s", test.In, s)
+		}
+	}
+	var perm Perm
+	var eerr *EnumError
+	if err := StringToInterface("read|delete", &perm); !errors.As(err, &eerr) || eerr.Name != "delete" {
+		t.Fatal("StringToInterface failed.")
+	}
+	if !reflect.DeepEqual(eerr.Allowed, []string{"read", "write", "rw", "exec"}) {
+		t.Fatal("StringToInterface failed.")
+	}
+	if err := StringToInterface("read|0x100000000", &perm); err == nil {
+		t.Fatal("StringToInterface failed.")
+	}
+	var features []Feature
+	if err := StringToInterface("A+B,0x80,b+0x4", &features); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(features, []Feature{3, -128, 6}) {
+		t.Fatal("StringToInterface failed.")
+	}
+	if s, err := InterfaceToString(features); err != nil || s != "a+b,last,b+0x4" {
+		t.Fatalf("InterfaceToString failed: %s", s)
+	}
+	if s, _ := InterfaceToString(Feature(-127)); s != "a+last" {
+		t.Fatalf("InterfaceToString failed: %s", s)
+	}
+	if err := RegisterFlags(map[string]Feature{"a|b": 1}, FlagOptions{}); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal("RegisterFlags failed.")
+	}
+	for _, sep := range []string{",", "=", "{", "}", "|,"} {
+		if err := RegisterFlags(map[string]Feature{"a": 1}, FlagOptions{Separator: sep}); !errors.Is(err, ErrInvalidArgument) {
+			t.Fatalf("RegisterFlags failed: %q", sep)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Match is a value found by FindAll.
+type Match struct {
+	// Path is the path to Value without wildcards, as understood by Find.
+	Path string
+	// Value is the found value.
+	Value reflect.Value
+}
+
+// FindAll searches for all Go values in a compound Go value specified by root
+// that match specified path and returns them in the order they are visited
+// by Walk.
+//
+// In addition to syntax understood by Find, path may contain following
+// elements:
+//
+// Any struct field, Slice or Array element or Map element:
+//
+//	Servers[*].Port
+//	Servers.*
+//
+// Any number of levels of values, including none:
+//
+//	**.Timeout
+//
+// Slice or Array elements from a low index up to but not including a high
+// index, where an omitted low index means 0, an omitted high index means
+// the length and negative indexes count from the end:
+//
+//	Hosts[1:3]
+//
+// Values that do not exist or do not match in any branch of the search are
+// skipped without an error. A pointer, Slice or Map that references a value
+// containing it, i.e. a cycle, is not descended into again by "**".
+func FindAll(path string, root interface{}) ([]Match, error) {
+	if root == nil {
+		return nil, ErrInvalidArgument
+	}
+	var segs, err = segments(path)
+	if err != nil {
+		return nil, err
+	}
+	var f = &finder{matched: make(map[string]bool), descending: make(map[descentKey]bool)}
+	if err = f.find("", reflect.ValueOf(root), segs); err != nil {
+		return nil, err
+	}
+	return f.matches, nil
+}
+
+// finder holds the state of a FindAll search.
+type finder struct {
+	matches    []Match
+	matched    map[string]bool     // paths already matched
+	descending map[descentKey]bool // values being descended into by "**"
+}
+
+// descentKey identifies a value descended into by a recursive descent
+// element followed by a number of remaining path elements.
+type descentKey struct {
+	ptrKey
+	remaining int
+}
+
+// find appends values matching segs in v, at path, to matches.
+func (f *finder) find(path string, v reflect.Value, segs []segment) error {
+	if len(segs) == 0 {
+		if !f.matched[path] {
+			f.matched[path] = true
+			f.matches = append(f.matches, Match{path, v})
+		}
+		return nil
+	}
+	var seg = segs[0]
+	if seg.token == RecursiveToken {
+		if err := f.find(path, v, segs[1:]); err != nil {
+			return err
+		}
+		if key, ok := pointerKey(v); ok {
+			var key = descentKey{key, len(segs)}
+			if f.descending[key] {
+				return nil
+			}
+			f.descending[key] = true
+			defer delete(f.descending, key)
+		}
+		return eachChild(path, v, func(path string, child reflect.Value) error {
+			return f.find(path, child, segs)
+		})
+	}
+	if v = indirect(v); !v.IsValid() {
+		return nil
+	}
+	switch seg.token {
+	case NameToken:
+		if v.Kind() != reflect.Struct {
+			return nil
+		}
+		var field, ok = v.Type().FieldByName(seg.name)
+		if !ok || field.PkgPath != "" {
+			return nil
+		}
+		var elem, err = fieldByIndex(v, field.Index, false)
+		if err != nil {
+			return nil
+		}
+		return f.find(joinName(path, seg.name), elem, segs[1:])
+	case KeyToken:
+		var elem, err = valueByKey(v, seg.key)
+		if err != nil {
+			return nil
+		}
+		if v.Kind() == reflect.Map {
+			return f.find(joinKey(path, quoteKey(seg.key)), elem, segs[1:])
+		}
+		return f.find(joinKey(path, seg.key), elem, segs[1:])
+	case WildcardToken:
+		return eachChild(path, v, func(path string, child reflect.Value) error {
+			return f.find(path, child, segs[1:])
+		})
+	case RangeToken:
+		if v.Kind() != reflect.Array && v.Kind() != reflect.Slice {
+			return nil
+		}
+		var low, high, err = parseRange(seg.key, v.Len())
+		if err != nil {
+			return err
+		}
+		for i := low; i < high; i++ {
+			if err = f.find(joinKey(path, strconv.Itoa(i)), v.Index(i), segs[1:]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseRange parses a range of indexes specified as "low:high" and returns
+// low and high clamped to length. Negative indexes count from the end.
+func parseRange(key string, length int) (low, high int, err error) {
+	var a = strings.Split(key, ":")
+	if len(a) != 2 {
+		return 0, 0, fmt.Errorf("%w: invalid range: %s", ErrInvalidPath, key)
+	}
+	if high = length; a[1] != "" {
+		if high, err = strconv.Atoi(a[1]); err != nil {
+			return 0, 0, fmt.Errorf("%w: invalid range: %s", ErrInvalidPath, key)
+		}
+	}
+	if a[0] != "" {
+		if low, err = strconv.Atoi(a[0]); err != nil {
+			return 0, 0, fmt.Errorf("%w: invalid range: %s", ErrInvalidPath, key)
+		}
+	}
+	if low < 0 {
+		if low += length; low < 0 {
+			low = 0
+		}
+	}
+	if high < 0 {
+		if high += length; high < 0 {
+			high = 0
+		}
+	}
+	if high > length {
+		high = length
+	}
+	if low > high {
+		low = high
+	}
+	return low, high, nil
+}
+
+// SetAll sets all Go values in a compound Go value specified by root that
+// match specified path to value converted by StringToValue. Root must be a
+// pointer. Values are matched as by FindAll and set as by Set. Returns the
+// number of values set, which may be less than the number of matches if an
+// error occurs.
+func SetAll(path, value string, root interface{}) (int, error) {
+	var matches, err = FindAll(path, root)
+	if err != nil {
+		return 0, err
+	}
+	for i, match := range matches {
+		if err = Set(match.Path, value, root); err != nil {
+			return i, fmt.Errorf("%s: %w", match.Path, err)
+		}
+	}
+	return len(matches), nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type FindAllServer struct {
+	Name    string
+	Port    int
+	Timeout int
+}
+
+type FindAllRoot struct {
+	Timeout int
+	Servers []FindAllServer
+	Map     map[string]FindAllServer
+	Hosts   []string
+}
+
+func getFindAllData() *FindAllRoot {
+	return &FindAllRoot{
+		Timeout: 1,
+		Servers: []FindAllServer{
+			{Name: "a", Port: 80, Timeout: 2},
+			{Name: "b", Port: 81, Timeout: 3},
+		},
+		Map: map[string]FindAllServer{
+			"*": {Name: "star", Port: 82, Timeout: 4},
+			"x": {Name: "x", Port: 83, Timeout: 5},
+		},
+		Hosts: []string{"a", "b", "c", "d"},
+	}
+}
+
+func matchPaths(matches []Match) (paths []string) {
+	for _, match := range matches {
+		paths = append(paths, match.Path)
+	}
+	return
+}
+
+func TestFindAll(t *testing.T) {
+	var tests = []struct {
+		Path   string
+		Expect []string
+	}{
+		{"Servers[*].Port", []string{"Servers[0].Port", "Servers[1].Port"}},
+		{"Map.*.Name", []string{`Map["*"].Name`, "Map[x].Name"}},
+		{`Map["*"].Name`, []string{`Map["*"].Name`}},
+		{"**.Timeout", []string{"Timeout", "Servers[0].Timeout", "Servers[1].Timeout", `Map["*"].Timeout`, "Map[x].Timeout"}},
+		{"Hosts[1:3]", []string{"Hosts[1]", "Hosts[2]"}},
+		{"Hosts[2:]", []string{"Hosts[2]", "Hosts[3]"}},
+		{"Hosts[:9]", []string{"Hosts[0]", "Hosts[1]", "Hosts[2]", "Hosts[3]"}},
+		{"Servers[*].Missing", nil},
+		{"Timeout", []string{"Timeout"}},
+		{"**[1]", []string{"Servers[1]", "Hosts[1]"}},
+	}
+	for _, test := range tests {
+		var matches, err = FindAll(test.Path, getFindAllData())
+		if err != nil {
+			t.Fatal(test.Path, err)
+		}
+		if paths := matchPaths(matches); !reflect.DeepEqual(paths, test.Expect) {
+			t.Fatalf("FindAll(%s) failed: want %v, got %v", test.Path, test.Expect, paths)
+		}
+	}
+	var matches, err = FindAll("Hosts[1:3]", getFindAllData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matches[0].Value.String() != "b" || matches[1].Value.String() != "c" {
+		t.Fatal("FindAll failed.")
+	}
+	if _, err = Find("Servers[*].Port", getFindAllData()); !errors.Is(err, ErrInvalidPath) {
+		t.Fatal("Find failed.")
+	}
+}
+
+func TestSetAll(t *testing.T) {
+	var data = getFindAllData()
+	var n, err = SetAll("**.Port", "8080", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 4 {
+		t.Fatalf("SetAll failed: want 4, got %d", n)
+	}
+	if data.Servers[0].Port != 8080 || data.Servers[1].Port != 8080 ||
+		data.Map["*"].Port != 8080 || data.Map["x"].Port != 8080 {
+		t.Fatal("SetAll failed.")
+	}
+	if n, err = SetAll("Hosts[:2]", "z", data); err != nil || n != 2 {
+		t.Fatal("SetAll failed.", err)
+	}
+	if !reflect.DeepEqual(data.Hosts, []string{"z", "z", "c", "d"}) {
+		t.Fatal("SetAll failed.")
+	}
+}
+
+func TestFindAllNegativeRange(t *testing.T) {
+	var tests = []struct {
+		Path   string
+		Expect []string
+	}{
+		{"Hosts[-2:]", []string{"Hosts[2]", "Hosts[3]"}},
+		{"Hosts[:-3]", []string{"Hosts[0]"}},
+		{"Hosts[-9:-2]", []string{"Hosts[0]", "Hosts[1]"}},
+		{"Hosts[-1:-2]", nil},
+	}
+	for _, test := range tests {
+		var matches, err = FindAll(test.Path, getFindAllData())
+		if err != nil {
+			t.Fatal(test.Path, err)
+		}
+		if paths := matchPaths(matches); !reflect.DeepEqual(paths, test.Expect) {
+			t.Fatalf("FindAll(%s) failed: want %v, got %v", test.Path, test.Expect, paths)
+		}
+	}
+}
+
+type FindAllNode struct {
+	Timeout int
+	Next    *FindAllNode
+	List    []interface{}
+}
+
+func TestFindAllCycle(t *testing.T) {
+	var node = &FindAllNode{Timeout: 1}
+	node.Next = node
+	node.List = []interface{}{node}
+	var matches, err = FindAll("**.Timeout", node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expect = []string{"Timeout", "Next.Timeout", "List[0].Timeout"}
+	if paths := matchPaths(matches); !reflect.DeepEqual(paths, expect) {
+		t.Fatalf("FindAll failed: want %v, got %v", expect, paths)
+	}
+	var n int
+	if n, err = SetAll("**.Timeout", "2", node); err != nil || n != 3 {
+		t.Fatal("SetAll failed.", err)
+	}
+	if node.Timeout != 2 {
+		t.Fatal("SetAll failed.")
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Flatten returns all simple values contained in root as a map of their
+// paths, as returned by Paths, to their string representations, as returned
+// by ValueToString. Non-nil compound values that contain no simple values,
+// such as empty Slices and Maps or Map elements of type struct{}, are
+// returned as their paths mapped to empty strings so that Unflatten can
+// recreate them.
+//
+// Unflatten of the result into a zero value of the type of root results in
+// a value equal to root, except that values held in interfaces cannot be
+// unflattened, unexported struct fields are not flattened, nil elements at
+// the end of Slices are dropped and values containing themselves are not
+// descended into more than once.
+func Flatten(root interface{}) (map[string]string, error) {
+	if root == nil {
+		return nil, ErrInvalidArgument
+	}
+	var result = make(map[string]string)
+	if err := flatten("", reflect.ValueOf(root), func(path, s string) {
+		result[path] = s
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// flatten calls f with the path and string representation of each simple
+// value contained in v and with the path and an empty string of each non-nil
+// compound value contained in v that contains no simple values, prefixing
+// paths with path. See Flatten.
+func flatten(path string, v reflect.Value, f func(path, s string)) error {
+	// parents holds the paths of compound values being walked and the number
+	// of calls to f when each was visited.
+	var parents []flattenParent
+	var count int
+	var leave = func(path string) {
+		for len(parents) > 0 {
+			var parent = parents[len(parents)-1]
+			if parent.contains(path) {
+				return
+			}
+			if parents = parents[:len(parents)-1]; count == parent.count {
+				f(parent.path, "")
+				count++
+			}
+		}
+	}
+	var s string
+	var err error
+	if err = walk(path, v, 0, -1, func(path string, value reflect.Value) error {
+		leave(path)
+		if value = indirect(value); !value.IsValid() || isNilContainer(value) {
+			return nil
+		}
+		if !isLeaf(value) {
+			parents = append(parents, flattenParent{path, count})
+			return nil
+		}
+		if s, err = valueToString(value); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		f(path, s)
+		count++
+		return nil
+	}); err != nil {
+		return err
+	}
+	leave("")
+	return nil
+}
+
+// flattenParent is a compound value being flattened.
+type flattenParent struct {
+	path  string
+	count int
+}
+
+// isNilContainer returns true if v is a nil Slice or Map.
+func isNilContainer(v reflect.Value) bool {
+	return (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.IsNil()
+}
+
+// contains returns true if path is a path to a value contained in parent.
+func (parent flattenParent) contains(path string) bool {
+	return len(path) > len(parent.path) && strings.HasPrefix(path, parent.path) &&
+		(path[len(parent.path)] == '.' || path[len(parent.path)] == '[')
+}
+
+// Unflatten sets values contained in root, which must be a pointer, from a
+// map of paths to their string representations as returned by Flatten.
+//
+// Values are set in sorted path order as if by Set except that Slices are
+// grown as needed to accommodate indexes in paths and an empty string sets
+// a Slice or a Map to an empty, non-nil value and an Array or a Struct that
+// does not convert from text itself to its zero value. Unlike Set, Unflatten
+// does not validate the values it sets.
+func Unflatten(values map[string]string, root interface{}) error {
+	if root == nil {
+		return ErrInvalidArgument
+	}
+	var paths = make([]string, 0, len(values))
+	for path := range values {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var segs []segment
+	var err error
+	for _, path := range paths {
+		if segs, err = segments(path); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		var value = values[path]
+		if err = modify(reflect.ValueOf(root), segs, true, func(v reflect.Value) error {
+			if !v.CanSet() {
+				return ErrUnaddressableValue
+			}
+			if value == "" && !isLeaf(v) {
+				switch v.Kind() {
+				case reflect.Slice:
+					v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+					return nil
+				case reflect.Map:
+					v.Set(reflect.MakeMap(v.Type()))
+					return nil
+				case reflect.Array, reflect.Struct:
+					v.Set(reflect.Zero(v.Type()))
+					return nil
+				}
+			}
+			return StringToValue(value, v)
+		}); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type FlattenChild struct {
+	Name string
+	Tags []string
+}
+
+type FlattenRoot struct {
+	Port   int
+	Time   time.Time
+	Child  *FlattenChild
+	Array  [2]float64
+	Map    map[int]FlattenChild
+	Labels map[string]string
+}
+
+func getFlattenData() *FlattenRoot {
+	return &FlattenRoot{
+		Port:  80,
+		Time:  time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Child: &FlattenChild{Name: "child", Tags: []string{"a", "b"}},
+		Array: [2]float64{1.5, 2.5},
+		Map: map[int]FlattenChild{
+			10: {Name: "ten", Tags: []string{"x"}},
+			2:  {Name: "two"},
+		},
+		Labels: map[string]string{"b": "2", "a": "1"},
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	var flat, err = Flatten(getFlattenData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expect = map[string]string{
+		"Port":            "80",
+		"Time":            "2020-01-02T03:04:05Z",
+		"Child.Name":      "child",
+		"Child.Tags[0]":   "a",
+		"Child.Tags[1]":   "b",
+		"Array[0]":        "1.5",
+		"Array[1]":        "2.5",
+		"Map[2].Name":     "two",
+		"Map[10].Name":    "ten",
+		"Map[10].Tags[0]": "x",
+		"Labels[a]":       "1",
+		"Labels[b]":       "2",
+	}
+	if !reflect.DeepEqual(flat, expect) {
+		t.Fatalf("Flatten failed: want %v, got %v", expect, flat)
+	}
+}
+
+func TestUnflatten(t *testing.T) {
+	var data = getFlattenData()
+	var flat, err = Flatten(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out = &FlattenRoot{}
+	if err = Unflatten(flat, out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(data, out) {
+		t.Fatalf("Unflatten failed: want %#v, got %#v", data, out)
+	}
+	if err = Unflatten(map[string]string{"Port": "foo"}, out); err == nil {
+		t.Fatal("Unflatten failed.")
+	}
+	if err = Unflatten(map[string]string{"Foo": "foo"}, out); !errors.Is(err, ErrInvalidPath) {
+		t.Fatal("Unflatten failed.")
+	}
+}
+
+type FlattenEmpty struct {
+	Labels map[string]string
+	Child  *FlattenChild
+	Set    map[string]struct{}
+	Ptrs   []*int
+	Nested map[string]struct{ Child *FlattenChild }
+	Empty  *struct{}
+	Nil    []int
+}
+
+func TestUnflattenEmpty(t *testing.T) {
+	var data = &FlattenEmpty{
+		Labels: map[string]string{},
+		Child:  &FlattenChild{Tags: []string{}},
+		Set:    map[string]struct{}{"a": {}, "b": {}},
+		Ptrs:   []*int{nil, new(int)},
+		Nested: map[string]struct{ Child *FlattenChild }{"a": {}},
+		Empty:  &struct{}{},
+	}
+	var flat, err = Flatten(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expect = map[string]string{
+		"Labels":     "",
+		"Child.Name": "",
+		"Child.Tags": "",
+		"Set[a]":     "",
+		"Set[b]":     "",
+		"Ptrs[1]":    "0",
+		"Nested[a]":  "",
+		"Empty":      "",
+	}
+	if !reflect.DeepEqual(flat, expect) {
+		t.Fatalf("Flatten failed: want %v, got %v", expect, flat)
+	}
+	var out = &FlattenEmpty{}
+	if err = Unflatten(flat, out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(data, out) {
+		t.Fatalf("Unflatten failed: want %#v, got %#v", data, out)
+	}
+}
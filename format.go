@@ -0,0 +1,178 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"encoding"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// InterfaceToString converts in to a string. See ValueToString for details.
+func InterfaceToString(in interface{}) (string, error) {
+	if in == nil {
+		return "", ErrInvalidArgument
+	}
+	return valueToString(reflect.ValueOf(in))
+}
+
+// ValueToString converts in to a string in the format understood by
+// StringToValue.
+//
+// Values implementing encoding.TextMarshaler are converted using it. Values
+// of enum types are converted to their names, see RegisterEnum. Nil
+// pointers are converted to empty strings. Map entries are emitted in sorted
+// key order.
+//
+// Chan and Func values will result in an error. Values nested more than
+// 10000 levels deep, such as values that contain themselves, result in
+// ErrTooDeep.
+func ValueToString(in reflect.Value) (string, error) {
+	if !in.IsValid() {
+		return "", ErrInvalidValue
+	}
+	return valueToString(in)
+}
+
+// maxFormatDepth is the maximum nesting depth of values converted to strings.
+const maxFormatDepth = 10000
+
+// valueToString converts in to a string. See ValueToString for details.
+func valueToString(in reflect.Value) (string, error) {
+	return formatValue(in, 0)
+}
+
+// formatValue converts in found at specified nesting depth to a string.
+func formatValue(in reflect.Value, depth int) (string, error) {
+	if depth > maxFormatDepth {
+		return "", ErrTooDeep
+	}
+	if in.CanInterface() {
+		if m, ok := in.Interface().(encoding.TextMarshaler); ok {
+			if in.Kind() == reflect.Ptr && in.IsNil() {
+				return "", nil
+			}
+			b, err := m.MarshalText()
+			if err != nil {
+				return "", err
+			}
+			return string(b), nil
+		}
+	}
+	switch in.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(in.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if e := enumFor(in.Type()); e != nil {
+			return e.format(in), nil
+		}
+		return strconv.FormatInt(in.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if e := enumFor(in.Type()); e != nil {
+			return e.format(in), nil
+		}
+		return strconv.FormatUint(in.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(in.Float(), 'g', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(in.Float(), 'g', -1, 64), nil
+	case reflect.Complex64:
+		return strconv.FormatComplex(in.Complex(), 'g', -1, 64), nil
+	case reflect.Complex128:
+		return strconv.FormatComplex(in.Complex(), 'g', -1, 128), nil
+	case reflect.String:
+		return in.String(), nil
+	case reflect.Array, reflect.Slice:
+		return arrayToString(in, depth)
+	case reflect.Map:
+		return mapToString(in, depth)
+	case reflect.Struct:
+		return structToString(in, depth)
+	case reflect.Ptr, reflect.Interface:
+		if in.IsNil() {
+			return "", nil
+		}
+		return formatValue(in.Elem(), depth+1)
+	}
+	return "", ErrUnsupportedValue
+}
+
+// arrayToString converts an Array or a Slice to a string of the form
+// "elem1,elem2,elemN".
+func arrayToString(in reflect.Value, depth int) (string, error) {
+	var a = make([]string, 0, in.Len())
+	for i := 0; i < in.Len(); i++ {
+		s, err := formatValue(in.Index(i), depth+1)
+		if err != nil {
+			return "", err
+		}
+		a = append(a, s)
+	}
+	return strings.Join(a, ","), nil
+}
+
+// mapToString converts a Map to a string of the form
+// "key1=val1,key2=val2,keyN=valN".
+func mapToString(in reflect.Value, depth int) (string, error) {
+	var a = make([]string, 0, in.Len())
+	for _, key := range sortedKeys(in) {
+		k, err := formatValue(key, depth+1)
+		if err != nil {
+			return "", err
+		}
+		v, err := formatValue(in.MapIndex(key), depth+1)
+		if err != nil {
+			return "", err
+		}
+		a = append(a, k+"="+v)
+	}
+	return strings.Join(a, ","), nil
+}
+
+// structToString converts a Struct to a string of the form
+// "{field1=value1,field2=value2,fieldN=valueN}". Unexported fields are
+// skipped. Fields with a BytesTag are converted in its encoding.
+func structToString(in reflect.Value, depth int) (string, error) {
+	var a = make([]string, 0, in.NumField())
+	for i := 0; i < in.NumField(); i++ {
+		if in.Type().Field(i).PkgPath != "" {
+			continue
+		}
+		v, err := fieldToString(in.Type().Field(i), in.Field(i), depth+1)
+		if err != nil {
+			return "", err
+		}
+		a = append(a, in.Type().Field(i).Name+"="+v)
+	}
+	return "{" + strings.Join(a, ",") + "}", nil
+}
+
+// sortedKeys returns keys of Map m sorted in ascending order. Numbers and
+// strings are ordered by value, booleans false first and keys of all other
+// kinds by their string representation.
+func sortedKeys(m reflect.Value) []reflect.Value {
+	var keys = m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		var a, b = keys[i], keys[j]
+		switch a.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return a.Int() < b.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return a.Uint() < b.Uint()
+		case reflect.Float32, reflect.Float64:
+			return a.Float() < b.Float()
+		case reflect.String:
+			return a.String() < b.String()
+		case reflect.Bool:
+			return !a.Bool() && b.Bool()
+		}
+		var sa, _ = valueToString(a)
+		var sb, _ = valueToString(b)
+		return sa < sb
+	})
+	return keys
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValueToString(t *testing.T) {
+	type Test struct {
+		Foo string
+		Bar int
+		baz bool
+	}
+	var i = 69
+	var tests = []struct {
+		In     interface{}
+		Expect string
+	}{
+		{true, "true"},
+		{-42, "-42"},
+		{uint8(42), "42"},
+		{float32(3.14), "3.14"},
+		{3.14, "3.14"},
+		{complex(3.14, 10), "(3.14+10i)"},
+		{"foo", "foo"},
+		{[3]int{1, 2, 3}, "1,2,3"},
+		{[]string{"a", "b"}, "a,b"},
+		{map[int]bool{10: true, 2: false}, "2=false,10=true"},
+		{Test{"foo", 42, true}, "{Foo=foo,Bar=42}"},
+		{&i, "69"},
+		{(*int)(nil), ""},
+		{time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), "2020-01-02T03:04:05Z"},
+	}
+	for _, test := range tests {
+		var s, err = ValueToString(reflect.ValueOf(test.In))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != test.Expect {
+			t.Fatalf("ValueToString(%T) failed: want '%s', got '%s'", test.In, test.Expect, s)
+		}
+	}
+	if _, err := ValueToString(reflect.ValueOf(make(chan int))); err != ErrUnsupportedValue {
+		t.Fatal("ValueToString(chan) failed")
+	}
+	if _, err := InterfaceToString(nil); err != ErrInvalidArgument {
+		t.Fatal("InterfaceToString(nil) failed")
+	}
+	var list = []interface{}{1, nil}
+	list[1] = list
+	if _, err := InterfaceToString(list); err != ErrTooDeep {
+		t.Fatal("InterfaceToString(cycle) failed")
+	}
+}
+
+func TestValueToStringRoundTrip(t *testing.T) {
+	var in = map[string]int{"a": 1, "b": 2}
+	var s, err = InterfaceToString(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]int
+	if err = StringToInterface(s, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatal("ValueToString round trip failed")
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// GetAs retrieves a Go value from a Go compound value by path as a value of
+// type T or returns an error.
+//
+// A value assignable to T is returned as is. A numeric value is converted to
+// a numeric T if it fits without overflow or loss of a fraction. A value of
+// any type is converted to a string T using ValueToString. Other values are
+// converted to T if they are of the same kind and convertible to T.
+func GetAs[T any](path string, root interface{}) (T, error) {
+	var out T
+	var v, err = Find(path, root)
+	if err != nil {
+		return out, err
+	}
+	var target = reflect.ValueOf(&out).Elem()
+	if target.Kind() == reflect.String && v.Kind() != reflect.String &&
+		!v.Type().AssignableTo(target.Type()) {
+		var s string
+		if s, err = ValueToString(v); err != nil {
+			return out, err
+		}
+		target.SetString(s)
+		return out, nil
+	}
+	if v, err = convertValue(v, target.Type()); err != nil {
+		return out, err
+	}
+	target.Set(v)
+	return out, nil
+}
+
+// MustGetAs is like GetAs but panics on error.
+func MustGetAs[T any](path string, root interface{}) T {
+	var out, err = GetAs[T](path, root)
+	if err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// SetValue sets a Go value inside a Go compound value specified by root by
+// path to value. Root must be a pointer.
+//
+// Value must be assignable to the target or convertible to it under the same
+// rules GetAs uses, except that values are not formatted to strings. Unlike
+// Set, SetValue does not validate value.
+func SetValue[T any](path string, value T, root interface{}) error {
+	var in = reflect.ValueOf(&value).Elem()
+	return modifyPath(path, root, func(v reflect.Value) error {
+		if !v.CanSet() {
+			return ErrUnaddressableValue
+		}
+		var cv, err = convertValue(in, v.Type())
+		if err != nil {
+			return err
+		}
+		v.Set(cv)
+		return nil
+	})
+}
+
+// MustSetValue is like SetValue but panics on error.
+func MustSetValue[T any](path string, value T, root interface{}) {
+	if err := SetValue(path, value, root); err != nil {
+		panic(err)
+	}
+}
+
+// convertValue returns v as a value of type t. V is returned as is if it is
+// assignable to t. Numbers are converted between numeric kinds if they fit
+// in t without overflow or loss of a fraction. Other values are converted if
+// they are of the same kind as t or between a string and a Slice and are
+// convertible to t.
+func convertValue(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	if v.Type().AssignableTo(t) {
+		return v, nil
+	}
+	var from, to = v.Kind(), t.Kind()
+	switch {
+	case isNumberKind(from) && isNumberKind(to):
+		if !fitsNumber(v, t) {
+			return reflect.Value{}, fmt.Errorf("%w: %v overflows %s", ErrInvalidValue, v, t)
+		}
+	case from == to,
+		from == reflect.String && to == reflect.Slice,
+		from == reflect.Slice && to == reflect.String:
+		if !v.Type().ConvertibleTo(t) {
+			return reflect.Value{}, fmt.Errorf("%w: cannot convert %s to %s", ErrInvalidValue, v.Type(), t)
+		}
+	default:
+		return reflect.Value{}, fmt.Errorf("%w: cannot convert %s to %s", ErrInvalidValue, v.Type(), t)
+	}
+	return v.Convert(t), nil
+}
+
+// isNumberKind returns true if k is an integer or a floating point kind.
+func isNumberKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
+
+// fitsNumber returns true if numeric value v can be converted to numeric type
+// t without overflow or loss of a fraction.
+func fitsNumber(v reflect.Value, t reflect.Type) bool {
+	var out = reflect.New(t).Elem()
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i = v.Int()
+		switch out.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return !out.OverflowInt(i)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return i >= 0 && !out.OverflowUint(uint64(i))
+		}
+		return true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u = v.Uint()
+		switch out.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return u <= math.MaxInt64 && !out.OverflowInt(int64(u))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return !out.OverflowUint(u)
+		}
+		return true
+	}
+	var f = v.Float()
+	switch out.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return !out.OverflowFloat(f)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 && !out.OverflowInt(int64(f))
+	}
+	return f == math.Trunc(f) && f >= 0 && f < math.MaxUint64 && !out.OverflowUint(uint64(f))
+}
+
+// ParseAs converts string s to a value of type T. It is named so because
+// Parse parses accessor paths.
+//
+// Unnamed basic types are converted directly by strconv without reflection,
+// other types are converted by StringToValue. Integer conversions fail with
+// a range error if the result does not fit in T in both cases.
+func ParseAs[T any](s string) (T, error) {
+	var out T
+	var err = ParseInto(s, &out)
+	return out, err
+}
+
+// MustParseAs is like ParseAs but panics on error.
+func MustParseAs[T any](s string) T {
+	var out, err = ParseAs[T](s)
+	if err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// ParseInto converts string s into the value out points to. See ParseAs for
+// details. Struct fields not specified in s keep their values. The value out
+// points to is not modified if s of an unnamed basic type fails to convert.
+func ParseInto[T any](s string, out *T) error {
+	if out == nil {
+		return ErrInvalidArgument
+	}
+	if ok, err := parseBasic(s, out); ok {
+		return err
+	}
+	return stringToValue(s, reflect.ValueOf(out).Elem())
+}
+
+// parseBasic converts s into out if out points to an unnamed basic type and
+// returns true or returns false otherwise. Out is not modified on error.
+func parseBasic(s string, out interface{}) (bool, error) {
+	var err error
+	switch p := out.(type) {
+	case *string:
+		*p = s
+	case *bool:
+		err = parseTo(p, s, strconv.ParseBool)
+	case *int:
+		err = parseIntTo(p, s, strconv.IntSize)
+	case *int8:
+		err = parseIntTo(p, s, 8)
+	case *int16:
+		err = parseIntTo(p, s, 16)
+	case *int32:
+		err = parseIntTo(p, s, 32)
+	case *int64:
+		err = parseIntTo(p, s, 64)
+	case *uint:
+		err = parseUintTo(p, s, strconv.IntSize)
+	case *uint8:
+		err = parseUintTo(p, s, 8)
+	case *uint16:
+		err = parseUintTo(p, s, 16)
+	case *uint32:
+		err = parseUintTo(p, s, 32)
+	case *uint64:
+		err = parseUintTo(p, s, 64)
+	case *uintptr:
+		err = parseUintTo(p, s, strconv.IntSize)
+	case *float32:
+		err = parseTo(p, s, func(s string) (float32, error) {
+			var n, err = strconv.ParseFloat(s, 32)
+			return float32(n), err
+		})
+	case *float64:
+		err = parseTo(p, s, func(s string) (float64, error) { return strconv.ParseFloat(s, 64) })
+	case *complex64:
+		err = parseTo(p, s, func(s string) (complex64, error) {
+			var n, err = strconv.ParseComplex(s, 64)
+			return complex64(n), err
+		})
+	case *complex128:
+		err = parseTo(p, s, func(s string) (complex128, error) { return strconv.ParseComplex(s, 128) })
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+// parseTo sets *p to s parsed by parse if it succeeds.
+func parseTo[T any](p *T, s string, parse func(string) (T, error)) error {
+	var n, err = parse(s)
+	if err != nil {
+		return err
+	}
+	*p = n
+	return nil
+}
+
+// parseIntTo sets *p to s parsed as a signed integer of bitSize bits if it
+// succeeds.
+func parseIntTo[T ~int | ~int8 | ~int16 | ~int32 | ~int64](p *T, s string, bitSize int) error {
+	var n, err = strconv.ParseInt(s, 10, bitSize)
+	if err != nil {
+		return err
+	}
+	*p = T(n)
+	return nil
+}
+
+// parseUintTo sets *p to s parsed as an unsigned integer of bitSize bits if
+// it succeeds.
+func parseUintTo[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr](p *T, s string, bitSize int) error {
+	var n, err = strconv.ParseUint(s, 10, bitSize)
+	if err != nil {
+		return err
+	}
+	*p = T(n)
+	return nil
+}
@@ -0,0 +1,228 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type Typed struct {
+	Int      int
+	Uint8    uint8
+	Float    float64
+	String   string
+	Duration time.Duration
+	Ints     []int
+	Labels   map[string]string
+	Stringer fmt.Stringer
+}
+
+func getTypedData() *Typed {
+	return &Typed{
+		Int:      42,
+		Uint8:    200,
+		Float:    1.5,
+		String:   "foo",
+		Duration: time.Second,
+		Ints:     []int{1, 2, 3},
+		Labels:   map[string]string{"b": "2", "a": "1"},
+		Stringer: time.Minute,
+	}
+}
+
+func TestGetAs(t *testing.T) {
+	var data = getTypedData()
+	if v, err := GetAs[int]("Int", data); err != nil || v != 42 {
+		t.Fatal("GetAs failed.", v, err)
+	}
+	if v, err := GetAs[int64]("Int", data); err != nil || v != 42 {
+		t.Fatal("GetAs failed.", v, err)
+	}
+	if v, err := GetAs[float32]("Uint8", data); err != nil || v != 200 {
+		t.Fatal("GetAs failed.", v, err)
+	}
+	if v, err := GetAs[time.Duration]("Int", data); err != nil || v != 42 {
+		t.Fatal("GetAs failed.", v, err)
+	}
+	if v, err := GetAs[string]("Int", data); err != nil || v != "42" {
+		t.Fatal("GetAs failed.", v, err)
+	}
+	if v, err := GetAs[string]("Duration", data); err != nil || v != "1000000000" {
+		t.Fatal("GetAs failed.", v, err)
+	}
+	if v, err := GetAs[string]("Labels", data); err != nil || v != "a=1,b=2" {
+		t.Fatal("GetAs failed.", v, err)
+	}
+	if v, err := GetAs[[]int]("Ints", data); err != nil || len(v) != 3 {
+		t.Fatal("GetAs failed.", v, err)
+	}
+	if v, err := GetAs[fmt.Stringer]("Stringer", data); err != nil || v != time.Minute {
+		t.Fatal("GetAs failed.", v, err)
+	}
+	if v, err := GetAs[interface{}]("Float", data); err != nil || v != 1.5 {
+		t.Fatal("GetAs failed.", v, err)
+	}
+	if _, err := GetAs[int8]("Uint8", data); !errors.Is(err, ErrInvalidValue) {
+		t.Fatal("GetAs failed.", err)
+	}
+	if _, err := GetAs[int]("Float", data); !errors.Is(err, ErrInvalidValue) {
+		t.Fatal("GetAs failed.", err)
+	}
+	if _, err := GetAs[bool]("Int", data); !errors.Is(err, ErrInvalidValue) {
+		t.Fatal("GetAs failed.", err)
+	}
+	if _, err := GetAs[int]("Missing", data); !errors.Is(err, ErrInvalidPath) {
+		t.Fatal("GetAs failed.", err)
+	}
+	if MustGetAs[uint]("Ints[-1]", data) != 3 {
+		t.Fatal("MustGetAs failed.")
+	}
+}
+
+func TestSetValue(t *testing.T) {
+	var data = getTypedData()
+	if err := SetValue("Int", 7, data); err != nil || data.Int != 7 {
+		t.Fatal("SetValue failed.", err)
+	}
+	if err := SetValue("Int", int64(8), data); err != nil || data.Int != 8 {
+		t.Fatal("SetValue failed.", err)
+	}
+	if err := SetValue("Uint8", 255, data); err != nil || data.Uint8 != 255 {
+		t.Fatal("SetValue failed.", err)
+	}
+	if err := SetValue("Duration", 2*time.Second, data); err != nil || data.Duration != 2*time.Second {
+		t.Fatal("SetValue failed.", err)
+	}
+	if err := SetValue("Float", 3, data); err != nil || data.Float != 3 {
+		t.Fatal("SetValue failed.", err)
+	}
+	if err := SetValue("Labels[c]", "3", data); err != nil || data.Labels["c"] != "3" {
+		t.Fatal("SetValue failed.", err)
+	}
+	if err := SetValue("Ints", []int{9}, data); err != nil || len(data.Ints) != 1 {
+		t.Fatal("SetValue failed.", err)
+	}
+	if err := SetValue("Stringer", time.Hour, data); err != nil || data.Stringer != time.Hour {
+		t.Fatal("SetValue failed.", err)
+	}
+	if err := SetValue("Uint8", 256, data); !errors.Is(err, ErrInvalidValue) || data.Uint8 != 255 {
+		t.Fatal("SetValue failed.", err)
+	}
+	if err := SetValue("Uint8", -1, data); !errors.Is(err, ErrInvalidValue) {
+		t.Fatal("SetValue failed.", err)
+	}
+	if err := SetValue("String", 65, data); !errors.Is(err, ErrInvalidValue) || data.String != "foo" {
+		t.Fatal("SetValue failed.", err)
+	}
+	if err := SetValue("Int", "1", data); !errors.Is(err, ErrInvalidValue) {
+		t.Fatal("SetValue failed.", err)
+	}
+	if err := SetValue("Int", 1, *data); !errors.Is(err, ErrUnaddressableValue) {
+		t.Fatal("SetValue failed.", err)
+	}
+	MustSetValue("String", "bar", data)
+	if data.String != "bar" {
+		t.Fatal("MustSetValue failed.")
+	}
+}
+
+func TestParseAs(t *testing.T) {
+	if v, err := ParseAs[uint16]("8080"); err != nil || v != 8080 {
+		t.Fatal("ParseAs failed.", v, err)
+	}
+	if v, err := ParseAs[int8]("-128"); err != nil || v != -128 {
+		t.Fatal("ParseAs failed.", v, err)
+	}
+	if _, err := ParseAs[int8]("300"); err == nil {
+		t.Fatal("ParseAs failed.")
+	}
+	if _, err := ParseAs[uint]("-1"); err == nil {
+		t.Fatal("ParseAs failed.")
+	}
+	if v, err := ParseAs[bool]("true"); err != nil || !v {
+		t.Fatal("ParseAs failed.", v, err)
+	}
+	if v, err := ParseAs[float32]("1.5"); err != nil || v != 1.5 {
+		t.Fatal("ParseAs failed.", v, err)
+	}
+	if v, err := ParseAs[complex128]("1+2i"); err != nil || v != 1+2i {
+		t.Fatal("ParseAs failed.", v, err)
+	}
+	if v, err := ParseAs[string]("foo"); err != nil || v != "foo" {
+		t.Fatal("ParseAs failed.", v, err)
+	}
+	if v, err := ParseAs[time.Duration]("42"); err != nil || v != 42 {
+		t.Fatal("ParseAs failed.", v, err)
+	}
+	if v, err := ParseAs[[]int]("1,2,3"); err != nil || len(v) != 3 || v[2] != 3 {
+		t.Fatal("ParseAs failed.", v, err)
+	}
+	if v, err := ParseAs[map[string]int]("a=1,b=2"); err != nil || v["b"] != 2 {
+		t.Fatal("ParseAs failed.", v, err)
+	}
+	if v, err := ParseAs[*int]("7"); err != nil || *v != 7 {
+		t.Fatal("ParseAs failed.", v, err)
+	}
+	if _, err := ParseAs[chan int]("1"); !errors.Is(err, ErrUnsupportedValue) {
+		t.Fatal("ParseAs failed.", err)
+	}
+	if MustParseAs[int]("-5") != -5 {
+		t.Fatal("MustParseAs failed.")
+	}
+}
+
+func TestParseInto(t *testing.T) {
+	var child = Child{Int: 1, String: "One"}
+	if err := ParseInto("{Int=2}", &child); err != nil || child.Int != 2 || child.String != "One" {
+		t.Fatal("ParseInto failed.", err)
+	}
+	var i int
+	if err := ParseInto("3", &i); err != nil || i != 3 {
+		t.Fatal("ParseInto failed.", err)
+	}
+	if err := ParseInto[int]("3", nil); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal("ParseInto failed.", err)
+	}
+	var i8 int8 = 5
+	if err := ParseInto("300", &i8); !errors.Is(err, strconv.ErrRange) || i8 != 5 {
+		t.Fatal("ParseInto failed.", err)
+	}
+	var f = 1.5
+	if err := ParseInto("x", &f); err == nil || f != 1.5 {
+		t.Fatal("ParseInto failed.", err)
+	}
+	var b = true
+	if err := ParseInto("x", &b); err == nil || !b {
+		t.Fatal("ParseInto failed.", err)
+	}
+	if err := StringToInterface("300", &i8); !errors.Is(err, strconv.ErrRange) {
+		t.Fatal("StringToInterface failed.", err)
+	}
+	var u16 uint16
+	if err := StringToInterface("70000", &u16); !errors.Is(err, strconv.ErrRange) {
+		t.Fatal("StringToInterface failed.", err)
+	}
+}
+
+func BenchmarkParseAs(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseAs[uint16]("8080"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStringToInterface(b *testing.B) {
+	var v uint16
+	for i := 0; i < b.N; i++ {
+		if err := StringToInterface("8080", &v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+var (
+	// ErrInputTooLong is returned when an input exceeds the maximum length.
+	ErrInputTooLong = fmt.Errorf("%w: input too long", ErrStrconvex)
+	// ErrTooManyElements is returned when a collection exceeds the maximum
+	// number of elements.
+	ErrTooManyElements = fmt.Errorf("%w: too many elements", ErrStrconvex)
+	// ErrTooDeep is returned when values exceed the maximum nesting depth.
+	ErrTooDeep = fmt.Errorf("%w: nesting too deep", ErrStrconvex)
+	// ErrTooManyAllocations is returned when decoding exceeds the maximum
+	// number of allocated values.
+	ErrTooManyAllocations = fmt.Errorf("%w: too many allocations", ErrStrconvex)
+	// ErrStringTooLong is returned when a string value exceeds the maximum
+	// length.
+	ErrStringTooLong = fmt.Errorf("%w: string too long", ErrStrconvex)
+)
+
+// Decoder converts strings to Go values like StringToValue with options and
+// limits suitable for decoding untrusted input. A limit of zero means no
+// limit. The zero Decoder has no limits and decodes like StringToValue.
+//
+// A Decoder is safe for concurrent use if its limits are not modified.
+type Decoder struct {
+	// MaxInputLength is the maximum length of the input in bytes.
+	MaxInputLength int
+	// MaxElements is the maximum number of elements of an Array, Slice or
+	// Map or the maximum number of fields of a Struct in the input.
+	MaxElements int
+	// MaxDepth is the maximum nesting depth of Arrays, Slices, Maps and
+	// Structs. A top level compound value is at depth 1.
+	MaxDepth int
+	// MaxAllocations is the maximum total number of values allocated while
+	// decoding a single input, counting every Slice and Map element, every
+	// pointed to value and every temporary Array and Struct field value.
+	MaxAllocations int
+	// MaxStringLength is the maximum length in bytes of a string value or of
+	// the text of a value decoded by encoding.TextUnmarshaler.
+	MaxStringLength int
+
+	// EmbeddedByTypeName allows Struct field names in the input to be
+	// qualified by the type names of the embedded structs that contain them,
+	// e.g. "Base.Port", which addresses fields shadowed by fields of the
+	// outer struct.
+	EmbeddedByTypeName bool
+
+	// ApplyDefaults sets zero Struct fields absent from the input to their
+	// default values as ApplyDefaults does. Fields present in the input are
+	// never set to defaults, even if set to zero values.
+	ApplyDefaults bool
+
+	// BytesEncoding is the encoding of []byte and [N]byte values without a
+	// BytesTag, e.g. "hex", as values of BytesTag are specified. If empty
+	// such values are decoded like StringToValue decodes them.
+	BytesEncoding string
+}
+
+// DecodeString converts string in to out which must be a pointer to a Go
+// value. See StringToValue for details.
+func (d *Decoder) DecodeString(in string, out interface{}) (err error) {
+	defer recoverError(&err)
+	if out == nil {
+		return ErrInvalidArgument
+	}
+	var v = reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return ErrInvalidArgument
+	}
+	return d.DecodeValue(in, v.Elem())
+}
+
+// DecodeValue converts string in to out. See StringToValue for details.
+func (d *Decoder) DecodeValue(in string, out reflect.Value) (err error) {
+	defer recoverError(&err)
+	if !out.IsValid() {
+		return ErrInvalidValue
+	}
+	if !decodable(out) {
+		return ErrUnaddressableValue
+	}
+	if d.MaxInputLength > 0 && len(in) > d.MaxInputLength {
+		return ErrInputTooLong
+	}
+	return decoderFor(out.Type())(&decodeState{decoder: d}, in, out)
+}
+
+// NewStreamDecoder returns a new StreamDecoder that reads from r and
+// enforces the limits of d. MaxInputLength limits the length of each
+// element, MaxElements the number of elements in the stream and the
+// other limits apply to each element separately.
+func (d *Decoder) NewStreamDecoder(r io.Reader) *StreamDecoder {
+	var sd = NewStreamDecoder(r)
+	sd.limits = d
+	return sd
+}
+
+// decodeState tracks the progress of a single decode against the options
+// and limits of a Decoder. Its methods are safe to call on a nil
+// *decodeState, which has no options and enforces no limits.
+type decodeState struct {
+	decoder  *Decoder
+	depth    int
+	allocs   int
+	defaults int // depth of default tag values being decoded
+}
+
+// newState returns a new *decodeState for d or nil if d is nil.
+func newState(d *Decoder) *decodeState {
+	if d == nil {
+		return nil
+	}
+	return &decodeState{decoder: d}
+}
+
+// enter enters a compound value with n elements.
+func (s *decodeState) enter(n int) error {
+	if s == nil {
+		return nil
+	}
+	if s.depth++; s.decoder.MaxDepth > 0 && s.depth > s.decoder.MaxDepth {
+		return ErrTooDeep
+	}
+	if s.decoder.MaxElements > 0 && n > s.decoder.MaxElements {
+		return ErrTooManyElements
+	}
+	return nil
+}
+
+// leave leaves a compound value.
+func (s *decodeState) leave() {
+	if s != nil {
+		s.depth--
+	}
+}
+
+// alloc accounts for n allocated values.
+func (s *decodeState) alloc(n int) error {
+	if s == nil {
+		return nil
+	}
+	if s.allocs += n; s.decoder.MaxAllocations > 0 && s.allocs > s.decoder.MaxAllocations {
+		return ErrTooManyAllocations
+	}
+	return nil
+}
+
+// embeddedByTypeName returns true if struct field names may be qualified by
+// the type names of embedded structs.
+func (s *decodeState) embeddedByTypeName() bool {
+	return s != nil && s.decoder.EmbeddedByTypeName
+}
+
+// applyDefaults returns true if defaults are applied to absent Struct
+// fields. Defaults are not applied to values decoded from default tags.
+func (s *decodeState) applyDefaults() bool {
+	return s != nil && s.decoder.ApplyDefaults && s.defaults == 0
+}
+
+// bytesEncoding returns the encoding of []byte and [N]byte values without a
+// BytesTag.
+func (s *decodeState) bytesEncoding() (bytesEncoding, error) {
+	if s == nil || s.decoder.BytesEncoding == "" {
+		return bytesList, nil
+	}
+	var enc, ok = bytesEncodings[s.decoder.BytesEncoding]
+	if !ok {
+		return bytesList, fmt.Errorf("%w: unknown bytes encoding %q", ErrInvalidArgument, s.decoder.BytesEncoding)
+	}
+	return enc, nil
+}
+
+// checkString checks the length of string value in.
+func (s *decodeState) checkString(in string) error {
+	if s != nil && s.decoder.MaxStringLength > 0 && len(in) > s.decoder.MaxStringLength {
+		return ErrStringTooLong
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecoderLimits(t *testing.T) {
+	var tests = []struct {
+		Decoder Decoder
+		In      string
+		Out     interface{}
+		Err     error
+	}{
+		{Decoder{}, "1,2,3", new([]int), nil},
+		{Decoder{MaxInputLength: 5}, "1,2,3", new([]int), nil},
+		{Decoder{MaxInputLength: 4}, "1,2,3", new([]int), ErrInputTooLong},
+		{Decoder{MaxElements: 3}, "1,2,3", new([]int), nil},
+		{Decoder{MaxElements: 2}, "1,2,3", new([]int), ErrTooManyElements},
+		{Decoder{MaxElements: 2}, "a=1,b=2,c=3", new(map[string]int), ErrTooManyElements},
+		{Decoder{MaxElements: 2}, "{ID=1,Name=foo,Score=1}", new(Row), ErrTooManyElements},
+		{Decoder{MaxElements: 2}, "1,2,3", new([2]int), ErrTooManyElements},
+		{Decoder{MaxDepth: 2}, "{Tags=a}", new(Row), nil},
+		{Decoder{MaxDepth: 1}, "{Tags=a}", new(Row), ErrTooDeep},
+		{Decoder{MaxDepth: 1}, "{Score=1}", new(Row), nil},
+		{Decoder{MaxAllocations: 3}, "1,2,3", new([]int), nil},
+		{Decoder{MaxAllocations: 2}, "1,2,3", new([]int), ErrTooManyAllocations},
+		{Decoder{MaxAllocations: 3}, "1,2", new([]*int), ErrTooManyAllocations},
+		{Decoder{MaxAllocations: 1}, "{ID=1,Name=foo}", new(Row), ErrTooManyAllocations},
+		{Decoder{MaxStringLength: 3}, "foo", new(string), nil},
+		{Decoder{MaxStringLength: 3}, "fooo", new(string), ErrStringTooLong},
+		{Decoder{MaxStringLength: 3}, "a=foo,b=barr", new(map[string]string), ErrStringTooLong},
+		{Decoder{MaxStringLength: 3}, "{Name=fooo}", new(Row), ErrStringTooLong},
+		{Decoder{MaxStringLength: 20}, "2020-01-02T03:04:05Z", new(time.Time), nil},
+		{Decoder{MaxStringLength: 10}, "2020-01-02T03:04:05Z", new(time.Time), ErrStringTooLong},
+		{Decoder{MaxStringLength: 10}, "2020-01-02T03:04:05Z", new(*time.Time), ErrStringTooLong},
+		{Decoder{MaxStringLength: 10}, "2020-01-02T03:04:05Z,2020-01-02T03:04:05Z", new([]time.Time), ErrStringTooLong},
+	}
+	for i, test := range tests {
+		var err = test.Decoder.DecodeString(test.In, test.Out)
+		if !errors.Is(err, test.Err) || (test.Err == nil) != (err == nil) {
+			t.Fatalf("DecodeString #%d (%s) failed: want %v, got %v", i, test.In, test.Err, err)
+		}
+		if err != nil && !errors.Is(err, ErrStrconvex) {
+			t.Fatalf("DecodeString #%d (%s) failed: %v does not wrap ErrStrconvex", i, test.In, err)
+		}
+	}
+	var d = &Decoder{}
+	if err := d.DecodeString("1", nil); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal("DecodeString failed.", err)
+	}
+	var i int
+	if err := d.DecodeString("1", i); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal("DecodeString failed.", err)
+	}
+	if err := d.DecodeString("42", &i); err != nil || i != 42 {
+		t.Fatal("DecodeString failed.", err)
+	}
+	var p DecoderPanic
+	if err := d.DecodeString("1", &p); !errors.Is(err, errPanic) || !errors.Is(err, ErrStrconvex) {
+		t.Fatal("DecodeString failed to recover.", err)
+	}
+	if err := d.DecodeValue("1", reflect.ValueOf(&p).Elem()); !errors.Is(err, errPanic) {
+		t.Fatal("DecodeValue failed to recover.", err)
+	}
+}
+
+type DecoderPanic struct{}
+
+func (p *DecoderPanic) UnmarshalText([]byte) error { panic("boom") }
+
+func TestDecoderStreamLimits(t *testing.T) {
+	var d = &Decoder{MaxElements: 3}
+	var ids []int
+	if err := d.NewStreamDecoder(strings.NewReader("1,2,3\n")).Decode(&ids); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.NewStreamDecoder(strings.NewReader("1,2,3,4")).Decode(&ids); !errors.Is(err, ErrTooManyElements) {
+		t.Fatal("Decode failed.", err)
+	}
+	d = &Decoder{MaxInputLength: 3}
+	if err := d.NewStreamDecoder(strings.NewReader("123,456\r\n")).Decode(&ids); err != nil {
+		t.Fatal(err)
+	}
+	var sd = d.NewStreamDecoder(strings.NewReader("123," + strings.Repeat("4", 1<<20)))
+	if _, err := sd.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sd.Next(); !errors.Is(err, ErrInputTooLong) {
+		t.Fatal("Next failed.", err)
+	}
+	if len(sd.span) > 1<<12 {
+		t.Fatal("Next buffered an element past the limit.")
+	}
+	d = &Decoder{MaxStringLength: 2}
+	var strs []string
+	if err := d.NewStreamDecoder(strings.NewReader("ab,abc")).Decode(&strs); !errors.Is(err, ErrStringTooLong) {
+		t.Fatal("Decode failed.", err)
+	}
+}
@@ -0,0 +1,492 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// patchOperation is a single RFC 6902 JSON Patch operation.
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ApplyPatch applies an RFC 6902 JSON Patch document to root which must be a
+// non-nil pointer. Paths are RFC 6901 JSON Pointers resolved as by
+// FindPointer.
+//
+// Operations add, remove, replace, move, copy and test are supported. JSON
+// values in the patch are converted to the type of their target: strings,
+// numbers and booleans by StringToValue from their text, null to a zero
+// value, arrays to Arrays and Slices element by element and objects to
+// Structs field by field and to Maps key by key.
+//
+// Removing a struct field sets it to zero. Adding to an Array sets the
+// element at the index as Arrays cannot grow. A failed test operation returns
+// an error wrapping ErrTestFailed. Values are not validated, see Validate.
+//
+// Operations store new values in place of the values they replace rather than
+// writing into them, so values the replaced ones shared with other variables
+// are left unchanged. If an operation fails the changes made by ApplyPatch
+// are undone in reverse order, restoring the values previously stored in
+// root and in the Maps, Slices and pointed to values it references.
+func ApplyPatch(patchJSON []byte, root interface{}) error {
+	var v = reflect.ValueOf(root)
+	if root == nil || v.Kind() != reflect.Ptr || v.IsNil() {
+		return ErrInvalidArgument
+	}
+	var ops []patchOperation
+	var err error
+	if err = json.Unmarshal(patchJSON, &ops); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	var j journal
+	for i, op := range ops {
+		if err = applyOperation(op, v, &j); err != nil {
+			j.undo(0)
+			var path string
+			if op.Path != nil {
+				path = *op.Path
+			}
+			return fmt.Errorf("operation %d (%s %s): %w", i, op.Op, strconv.Quote(path), err)
+		}
+	}
+	return nil
+}
+
+// applyOperation applies op to root and records the changes in j.
+func applyOperation(op patchOperation, root reflect.Value, j *journal) error {
+	if op.Path == nil {
+		return fmt.Errorf("%w: missing path", ErrInvalidPatch)
+	}
+	var path, err = parsePointer(*op.Path)
+	if err != nil {
+		return err
+	}
+	var from []string
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return fmt.Errorf("%w: missing value", ErrInvalidPatch)
+		}
+		var value interface{}
+		var dec = json.NewDecoder(bytes.NewReader(op.Value))
+		dec.UseNumber()
+		if err = dec.Decode(&value); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+		var assign = func(v reflect.Value) error { return assignJSON(value, v) }
+		switch op.Op {
+		case "add":
+			return patchAdd(path, root, assign, j)
+		case "replace":
+			return patchReplace(path, root, assign, j)
+		}
+		return patchTest(path, root, value)
+	case "move", "copy":
+		if op.From == nil {
+			return fmt.Errorf("%w: missing from", ErrInvalidPatch)
+		}
+		if from, err = parsePointer(*op.From); err != nil {
+			return err
+		}
+		var segs []segment
+		if segs, err = tokenSegments(from, root); err != nil {
+			return err
+		}
+		var value reflect.Value
+		if value, err = find(root, segs); err != nil {
+			return err
+		}
+		if op.Op == "copy" {
+			value = deepCopy(value, make(map[ptrKey]reflect.Value))
+		} else {
+			var moved = reflect.New(value.Type()).Elem()
+			moved.Set(value)
+			value = moved
+		}
+		if op.Op == "move" {
+			if *op.From == *op.Path {
+				return nil
+			}
+			if strings.HasPrefix(*op.Path, *op.From+"/") {
+				return fmt.Errorf("%w: cannot move a value into itself", ErrInvalidPatch)
+			}
+			if err = patchRemove(from, root, j); err != nil {
+				return err
+			}
+		}
+		return patchAdd(path, root, func(v reflect.Value) error {
+			return assignValue(value, v)
+		}, j)
+	case "remove":
+		return patchRemove(path, root, j)
+	}
+	return fmt.Errorf("%w: unknown operation: %s", ErrInvalidPatch, op.Op)
+}
+
+// patchAdd adds a value at path in root by calling assign with a settable
+// value that is then stored at path. Slice elements are inserted, "-" as the
+// last token appends to a Slice.
+func patchAdd(path []string, root reflect.Value, assign func(reflect.Value) error, j *journal) error {
+	return patchSet(path, root, assign, true, j)
+}
+
+// patchReplace replaces the value at path in root, which must exist, by
+// calling assign with it.
+func patchReplace(path []string, root reflect.Value, assign func(reflect.Value) error, j *journal) error {
+	var segs, err = tokenSegments(path, root)
+	if err != nil {
+		return err
+	}
+	if _, err = find(root, segs); err != nil {
+		return err
+	}
+	return patchSet(path, root, assign, false, j)
+}
+
+// patchSet sets a value at path in root by calling assign with a settable
+// zero value that is then stored at path and records the changes in j. If
+// insert is true Slice elements are inserted instead of replaced and "-" as
+// the last token appends to a Slice.
+func patchSet(path []string, root reflect.Value, assign func(reflect.Value) error, insert bool, j *journal) error {
+	var store = func(v reflect.Value) error {
+		if !v.CanSet() {
+			return ErrUnaddressableValue
+		}
+		var elem = reflect.New(v.Type()).Elem()
+		if err := assign(elem); err != nil {
+			return err
+		}
+		j.set(v, elem)
+		return nil
+	}
+	if len(path) == 0 {
+		return modifyAt(root, nil, 0, false, j, store)
+	}
+	var segs, err = tokenSegments(path[:len(path)-1], root)
+	if err != nil {
+		return err
+	}
+	var last = path[len(path)-1]
+	return modifyAt(root, segs, 0, false, j, func(v reflect.Value) error {
+		var elem reflect.Value
+		switch v.Kind() {
+		case reflect.Slice:
+			if !insert {
+				break
+			}
+			if !v.CanSet() {
+				return ErrUnaddressableValue
+			}
+			var i = v.Len()
+			if last != "-" {
+				if i, err = pointerIndex(last, v.Len(), true); err != nil {
+					return err
+				}
+			}
+			elem = reflect.New(v.Type().Elem()).Elem()
+			if err = assign(elem); err != nil {
+				return err
+			}
+			insertAt(v, i, elem, j)
+			return nil
+		case reflect.Map:
+			var key reflect.Value
+			if key, err = mapKey(v, last); err != nil {
+				return err
+			}
+			elem = reflect.New(v.Type().Elem()).Elem()
+			if err = assign(elem); err != nil {
+				return err
+			}
+			if v.IsNil() {
+				if !v.CanSet() {
+					return ErrUnaddressableValue
+				}
+				j.set(v, reflect.MakeMap(v.Type()))
+			}
+			j.setMapIndex(v, key, elem)
+			return nil
+		}
+		if elem, err = pointerChild(v, last); err != nil {
+			return err
+		}
+		return store(elem)
+	})
+}
+
+// patchRemove removes the value at path in root and records the changes in
+// j. Map and Slice elements are deleted, other values are set to zero.
+func patchRemove(path []string, root reflect.Value, j *journal) error {
+	if len(path) == 0 {
+		return fmt.Errorf("%w: cannot remove root", ErrInvalidPatch)
+	}
+	var segs, err = tokenSegments(path[:len(path)-1], root)
+	if err != nil {
+		return err
+	}
+	var last = path[len(path)-1]
+	return modifyAt(root, segs, 0, false, j, func(v reflect.Value) error {
+		switch v.Kind() {
+		case reflect.Slice:
+			var i int
+			if i, err = pointerIndex(last, v.Len(), false); err != nil {
+				return err
+			}
+			return removeAt(v, strconv.Itoa(i), j)
+		case reflect.Map:
+			var key reflect.Value
+			if key, err = mapKey(v, last); err != nil {
+				return err
+			}
+			if !v.MapIndex(key).IsValid() {
+				return fmt.Errorf("%w: key not found: %s", ErrInvalidPath, last)
+			}
+			j.setMapIndex(v, key, reflect.Value{})
+			return nil
+		}
+		var elem, err = pointerChild(v, last)
+		if err != nil {
+			return err
+		}
+		if !elem.CanSet() {
+			return ErrUnaddressableValue
+		}
+		j.set(elem, reflect.Zero(elem.Type()))
+		return nil
+	})
+}
+
+// patchTest tests that the value at path in root equals value.
+func patchTest(path []string, root reflect.Value, value interface{}) error {
+	var segs, err = tokenSegments(path, root)
+	if err != nil {
+		return err
+	}
+	var actual reflect.Value
+	if actual, err = find(root, segs); err != nil {
+		return err
+	}
+	var expect = reflect.New(actual.Type()).Elem()
+	if err = assignJSON(value, expect); err != nil {
+		return fmt.Errorf("%w: %v", ErrTestFailed, err)
+	}
+	if !reflect.DeepEqual(actual.Interface(), expect.Interface()) {
+		return ErrTestFailed
+	}
+	return nil
+}
+
+// pointerIndex converts an RFC 6901 JSON Pointer array index to an index
+// into a sequence of specified length. See parseIndex.
+func pointerIndex(token string, length int, end bool) (int, error) {
+	if !isPointerIndex(token) {
+		return 0, fmt.Errorf("%w: invalid array index: %s", ErrInvalidPath, token)
+	}
+	return parseIndex(token, length, end)
+}
+
+// pointerChild returns the struct field or Array or Slice element of v
+// specified by an RFC 6901 JSON Pointer reference token.
+func pointerChild(v reflect.Value, token string) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Struct:
+		var field, ok = structField(v.Type(), token)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("%w: field not found: %s", ErrInvalidPath, token)
+		}
+		return fieldByIndex(v, field.Index, true)
+	case reflect.Array, reflect.Slice:
+		var i, err = pointerIndex(token, v.Len(), false)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return v.Index(i), nil
+	}
+	return reflect.Value{}, ErrInvalidPath
+}
+
+// assignJSON assigns a value decoded from JSON with numbers decoded as
+// json.Number to settable out, converting it to the type of out.
+func assignJSON(in interface{}, out reflect.Value) error {
+	if in == nil {
+		out.Set(reflect.Zero(out.Type()))
+		return nil
+	}
+	if out.Kind() == reflect.Interface && out.NumMethod() == 0 {
+		out.Set(reflect.ValueOf(in))
+		return nil
+	}
+	switch val := in.(type) {
+	case string:
+		return StringToValue(val, out)
+	case json.Number:
+		return StringToValue(val.String(), out)
+	case bool:
+		return StringToValue(strconv.FormatBool(val), out)
+	}
+	for out.Kind() == reflect.Ptr {
+		if out.IsNil() {
+			out.Set(reflect.New(out.Type().Elem()))
+		}
+		out = out.Elem()
+	}
+	switch val := in.(type) {
+	case []interface{}:
+		switch out.Kind() {
+		case reflect.Slice:
+			var slice = reflect.MakeSlice(out.Type(), len(val), len(val))
+			for i := range val {
+				if err := assignJSON(val[i], slice.Index(i)); err != nil {
+					return err
+				}
+			}
+			out.Set(slice)
+			return nil
+		case reflect.Array:
+			if len(val) > out.Len() {
+				return fmt.Errorf("%w: array too long", ErrInvalidValue)
+			}
+			var array = reflect.New(out.Type()).Elem()
+			for i := range val {
+				if err := assignJSON(val[i], array.Index(i)); err != nil {
+					return err
+				}
+			}
+			out.Set(array)
+			return nil
+		}
+	case map[string]interface{}:
+		switch out.Kind() {
+		case reflect.Struct:
+			var result = reflect.New(out.Type()).Elem()
+			for name, fieldval := range val {
+				var field, ok = structField(out.Type(), name)
+				if !ok || field.PkgPath != "" {
+					return fmt.Errorf("%w: field not found: %s", ErrInvalidValue, name)
+				}
+				var fv, err = fieldByIndex(result, field.Index, true)
+				if err != nil {
+					return err
+				}
+				if err = assignJSON(fieldval, fv); err != nil {
+					return err
+				}
+			}
+			out.Set(result)
+			return nil
+		case reflect.Map:
+			var result = reflect.MakeMapWithSize(out.Type(), len(val))
+			for k, elemval := range val {
+				var key, err = mapKey(out, k)
+				if err != nil {
+					return err
+				}
+				var elem = reflect.New(out.Type().Elem()).Elem()
+				if err = assignJSON(elemval, elem); err != nil {
+					return err
+				}
+				result.SetMapIndex(key, elem)
+			}
+			out.Set(result)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: cannot convert %T to %s", ErrInvalidValue, in, out.Type())
+}
+
+// assignValue assigns in to settable out, dereferencing pointers in either
+// until in is assignable to out. Nil pointers in out are allocated.
+func assignValue(in, out reflect.Value) error {
+	for !in.Type().AssignableTo(out.Type()) {
+		switch {
+		case out.Kind() == reflect.Ptr:
+			if out.IsNil() {
+				out.Set(reflect.New(out.Type().Elem()))
+			}
+			out = out.Elem()
+		case in.Kind() == reflect.Ptr && !in.IsNil():
+			in = in.Elem()
+		default:
+			return fmt.Errorf("%w: cannot assign %s to %s", ErrInvalidValue, in.Type(), out.Type())
+		}
+	}
+	out.Set(in)
+	return nil
+}
+
+// deepCopy returns a copy of v that shares no Maps, Slices or pointed to
+// values reachable through exported fields with v. Unexported fields are
+// copied shallowly. Copies of values already copied are looked up in copies
+// so that cycles and values referenced more than once are copied once.
+func deepCopy(v reflect.Value, copies map[ptrKey]reflect.Value) reflect.Value {
+	var key ptrKey
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		if v.Kind() == reflect.Slice {
+			key.len = v.Len()
+		}
+		key.ptr, key.typ = v.Pointer(), v.Type()
+		if result, ok := copies[key]; ok {
+			return result
+		}
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		var result = reflect.New(v.Type().Elem())
+		copies[key] = result
+		result.Elem().Set(deepCopy(v.Elem(), copies))
+		return result
+	case reflect.Interface:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		var result = reflect.New(v.Type()).Elem()
+		result.Set(deepCopy(v.Elem(), copies))
+		return result
+	case reflect.Struct:
+		var result = reflect.New(v.Type()).Elem()
+		result.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				result.Field(i).Set(deepCopy(v.Field(i), copies))
+			}
+		}
+		return result
+	case reflect.Array:
+		var result = reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			result.Index(i).Set(deepCopy(v.Index(i), copies))
+		}
+		return result
+	case reflect.Slice:
+		var result = reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		copies[key] = result
+		for i := 0; i < v.Len(); i++ {
+			result.Index(i).Set(deepCopy(v.Index(i), copies))
+		}
+		return result
+	case reflect.Map:
+		var result = reflect.MakeMapWithSize(v.Type(), v.Len())
+		copies[key] = result
+		for _, key := range v.MapKeys() {
+			result.SetMapIndex(key, deepCopy(v.MapIndex(key), copies))
+		}
+		return result
+	}
+	return v
+}
@@ -0,0 +1,197 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type PatchServer struct {
+	Host    string        `json:"host"`
+	Port    uint16        `json:"port"`
+	Timeout time.Duration `json:"timeout"`
+}
+
+type PatchRoot struct {
+	Name    string                 `json:"name"`
+	Servers []PatchServer          `json:"servers"`
+	Labels  map[string]string      `json:"labels"`
+	Limits  map[string]int         `json:"limits"`
+	Backup  *PatchServer           `json:"backup"`
+	Started time.Time              `json:"started"`
+	Extra   map[string]interface{} `json:"extra"`
+}
+
+func getPatchData() *PatchRoot {
+	return &PatchRoot{
+		Name: "config",
+		Servers: []PatchServer{
+			{Host: "a", Port: 80},
+			{Host: "b", Port: 81},
+		},
+		Labels: map[string]string{"env": "prod"},
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	var data = getPatchData()
+	var patch = `[
+		{"op": "test", "path": "/name", "value": "config"},
+		{"op": "replace", "path": "/name", "value": "patched"},
+		{"op": "add", "path": "/servers/1", "value": {"host": "c", "port": 82, "timeout": 1000000000}},
+		{"op": "add", "path": "/servers/-", "value": {"host": "d", "port": 83}},
+		{"op": "remove", "path": "/servers/0"},
+		{"op": "add", "path": "/labels/team", "value": "ops"},
+		{"op": "move", "from": "/labels/env", "path": "/labels/stage"},
+		{"op": "copy", "from": "/servers/0", "path": "/backup"},
+		{"op": "replace", "path": "/backup/port", "value": 8080},
+		{"op": "add", "path": "/limits", "value": {"cpu": 2, "mem": 512}},
+		{"op": "add", "path": "/started", "value": "2020-01-02T03:04:05Z"},
+		{"op": "add", "path": "/extra", "value": {"a": [1, "b", null]}},
+		{"op": "test", "path": "/servers/0", "value": {"host": "c", "port": 82, "timeout": 1000000000}}
+	]`
+	if err := ApplyPatch([]byte(patch), data); err != nil {
+		t.Fatal(err)
+	}
+	var expect = &PatchRoot{
+		Name: "patched",
+		Servers: []PatchServer{
+			{Host: "c", Port: 82, Timeout: time.Second},
+			{Host: "b", Port: 81},
+			{Host: "d", Port: 83},
+		},
+		Labels:  map[string]string{"team": "ops", "stage": "prod"},
+		Limits:  map[string]int{"cpu": 2, "mem": 512},
+		Backup:  &PatchServer{Host: "c", Port: 8080, Timeout: time.Second},
+		Started: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	if data.Extra == nil {
+		t.Fatal("ApplyPatch failed.")
+	}
+	data.Extra = nil
+	if !reflect.DeepEqual(data, expect) {
+		t.Fatalf("ApplyPatch failed: want %#v, got %#v", expect, data)
+	}
+	if err := ApplyPatch([]byte(`[{"op": "replace", "path": "/backup", "value": null}]`), data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Backup != nil {
+		t.Fatal("ApplyPatch failed.")
+	}
+}
+
+func TestApplyPatchRollback(t *testing.T) {
+	var tests = []struct {
+		Patch string
+		Err   error
+	}{
+		{`[{"op": "replace", "path": "/name", "value": "x"}, {"op": "test", "path": "/name", "value": "y"}]`, ErrTestFailed},
+		{`[{"op": "remove", "path": "/servers/0"}, {"op": "remove", "path": "/servers/5"}]`, ErrInvalidPath},
+		{`[{"op": "add", "path": "/labels/x", "value": "y"}, {"op": "replace", "path": "/labels/missing", "value": "y"}]`, ErrInvalidPath},
+		{`[{"op": "add", "path": "/servers/0/port", "value": "foo"}]`, nil},
+		{`[{"op": "move", "from": "/servers", "path": "/servers/0"}]`, ErrInvalidPatch},
+		{`[{"op": "frobnicate", "path": "/name"}]`, ErrInvalidPatch},
+		{`[{"op": "add", "path": "/name"}]`, ErrInvalidPatch},
+		{`{}`, ErrInvalidPatch},
+	}
+	for _, test := range tests {
+		var data = getPatchData()
+		var err = ApplyPatch([]byte(test.Patch), data)
+		if err == nil || (test.Err != nil && !errors.Is(err, test.Err)) {
+			t.Fatalf("ApplyPatch(%s) failed: want %v, got %v", test.Patch, test.Err, err)
+		}
+		if !reflect.DeepEqual(data, getPatchData()) {
+			t.Fatalf("ApplyPatch(%s) failed to roll back: got %#v", test.Patch, data)
+		}
+	}
+}
+
+type PatchNode struct {
+	Name string      `json:"name"`
+	Next *PatchNode  `json:"next"`
+	Data interface{} `json:"data"`
+}
+
+func TestApplyPatchAlias(t *testing.T) {
+	var data = getPatchData()
+	data.Backup = &PatchServer{Host: "backup", Port: 90}
+	var backup, servers, labels = data.Backup, data.Servers, data.Labels
+	var patch = `[
+		{"op": "replace", "path": "/backup", "value": {"host": "x"}},
+		{"op": "remove", "path": "/servers/0"},
+		{"op": "add", "path": "/labels/team", "value": "ops"},
+		{"op": "test", "path": "/name", "value": "x"}
+	]`
+	if err := ApplyPatch([]byte(patch), data); !errors.Is(err, ErrTestFailed) {
+		t.Fatal("ApplyPatchAlias failed.")
+	}
+	if data.Backup != backup || *backup != (PatchServer{Host: "backup", Port: 90}) {
+		t.Fatal("ApplyPatchAlias failed.")
+	}
+	if &data.Servers[0] != &servers[0] || !reflect.DeepEqual(servers, getPatchData().Servers) {
+		t.Fatal("ApplyPatchAlias failed.")
+	}
+	if reflect.ValueOf(data.Labels).Pointer() != reflect.ValueOf(labels).Pointer() || len(labels) != 1 {
+		t.Fatal("ApplyPatchAlias failed.")
+	}
+}
+
+func TestApplyPatchCycle(t *testing.T) {
+	var data = &PatchNode{Name: "a"}
+	data.Next = data
+	if err := ApplyPatch([]byte(`[{"op": "copy", "from": "/next", "path": "/data"}]`), data); err != nil {
+		t.Fatal(err)
+	}
+	var copied, ok = data.Data.(*PatchNode)
+	if !ok || copied == data || copied.Next != copied || copied.Name != "a" {
+		t.Fatal("ApplyPatchCycle failed.")
+	}
+	data.Data = nil
+	var patch = `[
+		{"op": "replace", "path": "/next/next/name", "value": "b"},
+		{"op": "test", "path": "/name", "value": "a"}
+	]`
+	if err := ApplyPatch([]byte(patch), data); !errors.Is(err, ErrTestFailed) {
+		t.Fatal("ApplyPatchCycle failed.")
+	}
+	if data.Name != "a" || data.Next != data {
+		t.Fatal("ApplyPatchCycle failed.")
+	}
+}
+
+func TestApplyPatchInterface(t *testing.T) {
+	var data = &PatchNode{
+		Data: map[string]interface{}{"a": "1"},
+		Next: &PatchNode{Data: PatchServer{Host: "a"}},
+	}
+	var patch = `[
+		{"op": "add", "path": "/data/b", "value": 2},
+		{"op": "replace", "path": "/next/data/port", "value": 80}
+	]`
+	if err := ApplyPatch([]byte(patch), data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(data.Data, map[string]interface{}{"a": "1", "b": json.Number("2")}) {
+		t.Fatal("ApplyPatchInterface failed.")
+	}
+	if data.Next.Data != (PatchServer{Host: "a", Port: 80}) {
+		t.Fatal("ApplyPatchInterface failed.")
+	}
+	patch = `[
+		{"op": "remove", "path": "/data/a"},
+		{"op": "replace", "path": "/next/data/host", "value": "b"},
+		{"op": "test", "path": "/next/data/host", "value": "a"}
+	]`
+	if err := ApplyPatch([]byte(patch), data); !errors.Is(err, ErrTestFailed) {
+		t.Fatal("ApplyPatchInterface failed.")
+	}
+	if len(data.Data.(map[string]interface{})) != 2 || data.Next.Data != (PatchServer{Host: "a", Port: 80}) {
+		t.Fatal("ApplyPatchInterface failed.")
+	}
+}
@@ -0,0 +1,385 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FindPointer is like Find but takes an RFC 6901 JSON Pointer instead of a
+// path. An empty pointer refers to root itself.
+//
+// Reference tokens address struct fields by their name in the json struct
+// tag, by their name, or by their name compared case-insensitively, in that
+// order. They address Map elements by key and Array and Slice elements by a
+// non-negative index.
+//
+// For example:
+//
+//	/servers/0/port
+func FindPointer(pointer string, root interface{}) (reflect.Value, error) {
+	if root == nil {
+		return reflect.Value{}, ErrInvalidArgument
+	}
+	var segs, err = pointerSegments(pointer, reflect.ValueOf(root))
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return find(reflect.ValueOf(root), segs)
+}
+
+// MustFindPointer is like FindPointer but panics on error.
+func MustFindPointer(pointer string, root interface{}) (v reflect.Value) {
+	var err error
+	if v, err = FindPointer(pointer, root); err != nil {
+		panic(err)
+	}
+	return
+}
+
+// GetPointer is like Get but takes an RFC 6901 JSON Pointer instead of a path.
+// See FindPointer for details.
+func GetPointer(pointer string, root interface{}) (interface{}, error) {
+	var val, err = FindPointer(pointer, root)
+	if err != nil {
+		return nil, err
+	}
+	return val.Interface(), nil
+}
+
+// SetPointer is like Set but takes an RFC 6901 JSON Pointer instead of a path.
+// See FindPointer for details.
+func SetPointer(pointer, value string, root interface{}) error {
+	if root == nil {
+		return ErrInvalidArgument
+	}
+	var segs, err = pointerSegments(pointer, reflect.ValueOf(root))
+	if err != nil {
+		return err
+	}
+	return modify(reflect.ValueOf(root), segs, false, setString(value, segs, newSetTarget(segs, reflect.TypeOf(root))))
+}
+
+// PointerToPath converts an RFC 6901 JSON Pointer to a path that addresses
+// the same value in root. See FindPointer for details.
+func PointerToPath(pointer string, root interface{}) (string, error) {
+	if root == nil {
+		return "", ErrInvalidArgument
+	}
+	var segs, err = pointerSegments(pointer, reflect.ValueOf(root))
+	if err != nil {
+		return "", err
+	}
+	return segmentsToPath(segs), nil
+}
+
+// PathToPointer converts a path to an RFC 6901 JSON Pointer that addresses
+// the same value in root. Struct fields are named by their name in the json
+// struct tag if it is specified. Path may not contain wildcards.
+func PathToPointer(path string, root interface{}) (string, error) {
+	if root == nil {
+		return "", ErrInvalidArgument
+	}
+	var segs, err = segments(path)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	var w = &typeWalker{v: reflect.ValueOf(root), t: reflect.TypeOf(root)}
+	for _, seg := range segs {
+		if err = w.deref(); err != nil {
+			return "", err
+		}
+		var token string
+		switch seg.token {
+		case NameToken:
+			if w.t.Kind() != reflect.Struct {
+				return "", ErrInvalidPath
+			}
+			var field, err = exportedField(w.t, seg.name)
+			if err != nil {
+				return "", err
+			}
+			if jsonIgnored(w.t, field.Index) {
+				return "", fmt.Errorf("%w: field ignored by json: %s", ErrInvalidPath, seg.name)
+			}
+			if token = jsonName(field); token == "" {
+				token = field.Name
+			}
+			w.field(field)
+		case KeyToken:
+			if w.t.Kind() == reflect.Array || w.t.Kind() == reflect.Slice {
+				if w.v.IsValid() {
+					var i int
+					if i, err = parseIndex(seg.key, w.v.Len(), false); err != nil {
+						return "", err
+					}
+					seg.key = strconv.Itoa(i)
+				} else if _, err = strconv.ParseUint(seg.key, 10, 0); err != nil {
+					return "", fmt.Errorf("%w: element to index: %v", ErrInvalidPath, err)
+				}
+			}
+			token = seg.key
+			if err = w.elem(seg.key); err != nil {
+				return "", err
+			}
+		default:
+			return "", errWildcard
+		}
+		sb.WriteByte('/')
+		sb.WriteString(escapePointerToken(token))
+	}
+	return sb.String(), nil
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into unescaped reference
+// tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("%w: pointer must start with '/'", ErrInvalidPath)
+	}
+	var tokens = strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		for j := 0; j < len(token); j++ {
+			if token[j] == '~' && (j+1 == len(token) || (token[j+1] != '0' && token[j+1] != '1')) {
+				return nil, fmt.Errorf("%w: invalid pointer escape: %s", ErrInvalidPath, token)
+			}
+		}
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+// escapePointerToken escapes token for use in an RFC 6901 JSON Pointer.
+func escapePointerToken(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
+
+// pointerSegments converts an RFC 6901 JSON Pointer to segments that address
+// the same value in root.
+func pointerSegments(pointer string, root reflect.Value) ([]segment, error) {
+	var tokens, err = parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	return tokenSegments(tokens, root)
+}
+
+// tokenSegments converts unescaped RFC 6901 JSON Pointer reference tokens to
+// segments that address the same value in root.
+func tokenSegments(tokens []string, root reflect.Value) ([]segment, error) {
+	var err error
+	var segs = make([]segment, 0, len(tokens))
+	var w = &typeWalker{v: root, t: root.Type()}
+	for _, token := range tokens {
+		if err = w.deref(); err != nil {
+			return nil, err
+		}
+		switch w.t.Kind() {
+		case reflect.Struct:
+			var field, ok = structField(w.t, token)
+			if !ok {
+				return nil, fmt.Errorf("%w: field not found: %s", ErrInvalidPath, token)
+			}
+			if field.PkgPath != "" {
+				return nil, fmt.Errorf("%w: %s", ErrUnexportedField, token)
+			}
+			segs = append(segs, segment{token: NameToken, name: field.Name})
+			w.field(field)
+		case reflect.Array, reflect.Slice:
+			if !isPointerIndex(token) {
+				return nil, fmt.Errorf("%w: invalid array index: %s", ErrInvalidPath, token)
+			}
+			fallthrough
+		case reflect.Map:
+			segs = append(segs, segment{token: KeyToken, key: token})
+			if err = w.elem(token); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, ErrInvalidPath
+		}
+	}
+	return segs, nil
+}
+
+// isPointerIndex returns true if token is an RFC 6901 JSON Pointer array
+// index; a non-negative decimal number without leading zeros.
+func isPointerIndex(token string) bool {
+	if token == "" || token[0] == '-' || (len(token) > 1 && token[0] == '0') {
+		return false
+	}
+	return isIndex(token)
+}
+
+// structField returns a field of struct type t that name refers to by json
+// struct tag name, field name or case-insensitive field name, in that order.
+// Fields ignored by json, see jsonIgnored, never match.
+func structField(t reflect.Type, name string) (reflect.StructField, bool) {
+	var field reflect.StructField
+	var ok bool
+	if field, ok = jsonField(t, name); ok {
+		return field, true
+	}
+	if field, ok = t.FieldByName(name); ok && !jsonIgnored(t, field.Index) {
+		return field, true
+	}
+	if field, ok = t.FieldByNameFunc(func(s string) bool { return strings.EqualFold(s, name) }); ok && !jsonIgnored(t, field.Index) {
+		return field, true
+	}
+	return reflect.StructField{}, false
+}
+
+// jsonIgnored returns true if the nested field of struct type t specified by
+// index, or an embedded struct it is promoted from, has a json struct tag of
+// "-" which makes json ignore it.
+func jsonIgnored(t reflect.Type, index []int) bool {
+	for _, i := range index {
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		var field = t.Field(i)
+		if field.Tag.Get("json") == "-" {
+			return true
+		}
+		t = field.Type
+	}
+	return false
+}
+
+// jsonField returns a field of struct type t, including fields promoted from
+// embedded structs, whose json struct tag name is name. Fields without a json
+// struct tag name never match.
+func jsonField(t reflect.Type, name string) (reflect.StructField, bool) {
+	if name == "" {
+		return reflect.StructField{}, false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		var field = t.Field(i)
+		if jsonName(field) == name {
+			return field, true
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		var field = t.Field(i)
+		if !field.Anonymous || jsonName(field) != "" || field.Tag.Get("json") == "-" {
+			continue
+		}
+		var ft = field.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() != reflect.Struct {
+			continue
+		}
+		if promoted, ok := jsonField(ft, name); ok {
+			promoted.Index = append([]int{i}, promoted.Index...)
+			return promoted, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// jsonName returns the name of field from its json struct tag or an empty
+// string if it is not specified or the field is ignored by json.
+func jsonName(field reflect.StructField) string {
+	var tag = field.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if i := strings.IndexByte(tag, ','); i >= 0 {
+		tag = tag[:i]
+	}
+	return tag
+}
+
+// typeWalker follows a path through a type and, while it exists, a value of
+// that type.
+type typeWalker struct {
+	t reflect.Type
+	v reflect.Value
+}
+
+// deref dereferences pointers and interfaces. Interfaces can only be
+// dereferenced while the walked value exists.
+func (w *typeWalker) deref() error {
+	for {
+		switch w.t.Kind() {
+		case reflect.Ptr:
+			w.t = w.t.Elem()
+			if w.v.IsValid() {
+				if w.v.IsNil() {
+					w.v = reflect.Value{}
+				} else {
+					w.v = w.v.Elem()
+				}
+			}
+		case reflect.Interface:
+			if !w.v.IsValid() || w.v.IsNil() {
+				return ErrInvalidPath
+			}
+			w.v = w.v.Elem()
+			w.t = w.v.Type()
+		default:
+			return nil
+		}
+	}
+}
+
+// field steps into a struct field.
+func (w *typeWalker) field(field reflect.StructField) {
+	w.t = field.Type
+	if !w.v.IsValid() {
+		return
+	}
+	for i, index := range field.Index {
+		if i > 0 {
+			if w.v.Kind() == reflect.Ptr {
+				if w.v.IsNil() {
+					w.v = reflect.Value{}
+					return
+				}
+				w.v = w.v.Elem()
+			}
+		}
+		w.v = w.v.Field(index)
+	}
+}
+
+// elem steps into an Array, Slice or Map element specified by key.
+func (w *typeWalker) elem(key string) error {
+	switch w.t.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map:
+	default:
+		return ErrInvalidPath
+	}
+	if w.t = w.t.Elem(); !w.v.IsValid() {
+		return nil
+	}
+	if w.v.Kind() == reflect.Map {
+		var mapkey, err = mapKey(w.v, key)
+		if err != nil {
+			return err
+		}
+		w.v = w.v.MapIndex(mapkey)
+		return nil
+	}
+	var i, err = strconv.Atoi(key)
+	if err != nil {
+		return fmt.Errorf("%w: element to index: %v", ErrInvalidPath, err)
+	}
+	if i < 0 || i >= w.v.Len() {
+		w.v = reflect.Value{}
+		return nil
+	}
+	w.v = w.v.Index(i)
+	return nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"errors"
+	"testing"
+)
+
+type PointerServer struct {
+	Port int    `json:"port,omitempty"`
+	Host string `json:"-"`
+}
+
+type PointerEmbedded struct {
+	Level int `json:"level"`
+}
+
+type PointerIgnored struct {
+	Secret string
+}
+
+type PointerRoot struct {
+	PointerEmbedded
+	PointerIgnored `json:"-"`
+	Ignored        int                     `json:"-"`
+	Servers        []PointerServer         `json:"servers"`
+	Labels         map[string]string       `json:"labels"`
+	Nested         map[string]*PointerRoot `json:"nested"`
+	Name           string
+}
+
+func getPointerData() *PointerRoot {
+	return &PointerRoot{
+		PointerEmbedded: PointerEmbedded{Level: 3},
+		Servers:         []PointerServer{{Port: 80, Host: "a"}, {Port: 81, Host: "b"}},
+		Labels:          map[string]string{"a/b": "slash", "m~n": "tilde", "": "empty"},
+		Nested: map[string]*PointerRoot{
+			"x": {Name: "nested"},
+		},
+		Name:           "root",
+		PointerIgnored: PointerIgnored{Secret: "secret"},
+		Ignored:        1,
+	}
+}
+
+func TestFindPointer(t *testing.T) {
+	var tests = []struct {
+		Pointer string
+		Expect  interface{}
+	}{
+		{"/servers/1/port", 81},
+		{"/labels/a~1b", "slash"},
+		{"/labels/m~0n", "tilde"},
+		{"/labels/", "empty"},
+		{"/level", 3},
+		{"/Name", "root"},
+		{"/name", "root"},
+		{"/nested/x/name", "nested"},
+	}
+	for _, test := range tests {
+		var val, err = GetPointer(test.Pointer, getPointerData())
+		if err != nil {
+			t.Fatal(test.Pointer, err)
+		}
+		if val != test.Expect {
+			t.Fatalf("GetPointer(%s) failed: want %v, got %v", test.Pointer, test.Expect, val)
+		}
+	}
+	for _, pointer := range []string{
+		"servers", "/servers/01", "/servers/-", "/servers/-1", "/servers/5", "/labels/~2", "/missing",
+		"/servers/0/Host", "/servers/0/host", "/ignored", "/Ignored", "/Secret", "/secret",
+	} {
+		if _, err := FindPointer(pointer, getPointerData()); !errors.Is(err, ErrInvalidPath) {
+			t.Fatalf("FindPointer(%s) failed: want ErrInvalidPath, got %v", pointer, err)
+		}
+	}
+	var val, err = FindPointer("", getPointerData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val.Interface().(*PointerRoot).Name != "root" {
+		t.Fatal("FindPointer failed.")
+	}
+	if _, err = FindPointer("/", &struct{ A int }{7}); !errors.Is(err, ErrInvalidPath) {
+		t.Fatal("FindPointer failed.")
+	}
+	if _, err = FindPointer("/", &struct {
+		A int
+		B int `json:""`
+		C int `json:",omitempty"`
+	}{7, 8, 9}); err == nil {
+		t.Fatal("FindPointer failed.")
+	}
+}
+
+func TestSetPointer(t *testing.T) {
+	var data = getPointerData()
+	if err := SetPointer("/servers/0/port", "8080", data); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetPointer("/labels/c~1d", "new", data); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetPointer("/nested/y/level", "7", data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Servers[0].Port != 8080 || data.Labels["c/d"] != "new" || data.Nested["y"].Level != 7 {
+		t.Fatal("SetPointer failed.")
+	}
+}
+
+func TestPointerToPath(t *testing.T) {
+	var tests = []struct {
+		Pointer string
+		Path    string
+	}{
+		{"/servers/1/port", "Servers[1].Port"},
+		{"/labels/m~0n", "Labels[m~n]"},
+		{"/labels/a~1b", "Labels[a/b]"},
+		{"/level", "Level"},
+		{"/nested/y/labels/z", "Nested[y].Labels[z]"},
+	}
+	for _, test := range tests {
+		var path, err = PointerToPath(test.Pointer, getPointerData())
+		if err != nil {
+			t.Fatal(test.Pointer, err)
+		}
+		if path != test.Path {
+			t.Fatalf("PointerToPath(%s) failed: want %s, got %s", test.Pointer, test.Path, path)
+		}
+		var pointer string
+		if pointer, err = PathToPointer(path, getPointerData()); err != nil {
+			t.Fatal(path, err)
+		}
+		if pointer != test.Pointer {
+			t.Fatalf("PathToPointer(%s) failed: want %s, got %s", path, test.Pointer, pointer)
+		}
+	}
+	var pointer, err = PathToPointer("Servers[-1].Port", getPointerData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pointer != "/servers/1/port" {
+		t.Fatalf("PathToPointer failed: want /servers/1/port, got %s", pointer)
+	}
+	for _, path := range []string{"Servers[0].Host", "Ignored", "Secret"} {
+		if _, err = PathToPointer(path, getPointerData()); !errors.Is(err, ErrInvalidPath) {
+			t.Fatalf("PathToPointer(%s) failed: want ErrInvalidPath, got %v", path, err)
+		}
+	}
+	if _, err = PathToPointer("Servers[*]", getPointerData()); !errors.Is(err, ErrInvalidPath) {
+		t.Fatal("PathToPointer failed.")
+	}
+}
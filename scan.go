@@ -0,0 +1,54 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"fmt"
+	"strings"
+)
+
+// errSyntax is returned when a compound value has invalid syntax.
+var errSyntax = fmt.Errorf("%w: syntax error", ErrStrconvex)
+
+// scanner yields the spans of a list of values delimited by a comma from a
+// string in a single pass without allocating. Spans are substrings of the
+// scanned string. A scanner is used as a value; its zero value is not
+// usable.
+type scanner struct {
+	s   string
+	pos int
+}
+
+// newScanner returns a scanner over s.
+func newScanner(s string) scanner { return scanner{s: s} }
+
+// next returns the next span and true or an empty string and false if the
+// list is exhausted. An empty list yields a single empty span.
+func (sc *scanner) next() (string, bool) {
+	if sc.pos > len(sc.s) {
+		return "", false
+	}
+	var rest = sc.s[sc.pos:]
+	var i = strings.IndexByte(rest, ',')
+	if i < 0 {
+		sc.pos = len(sc.s) + 1
+		return rest, true
+	}
+	sc.pos += i + 1
+	return rest[:i], true
+}
+
+// count returns the number of spans in a list in s.
+func count(s string) int { return strings.Count(s, ",") + 1 }
+
+// splitPair splits a span of the form key=value into key and value. Returns
+// false if span does not contain exactly one '='.
+func splitPair(span string) (key, value string, ok bool) {
+	var i = strings.IndexByte(span, '=')
+	if i < 0 || strings.IndexByte(span[i+1:], '=') >= 0 {
+		return "", "", false
+	}
+	return span[:i], span[i+1:], true
+}
@@ -0,0 +1,71 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScanner(t *testing.T) {
+	for _, in := range []string{"", "a", "a,b", ",", "a,,b,", " a , b "} {
+		var spans []string
+		for sc := newScanner(in); ; {
+			span, ok := sc.next()
+			if !ok {
+				break
+			}
+			spans = append(spans, span)
+		}
+		if want := strings.Split(in, ","); !reflect.DeepEqual(spans, want) {
+			t.Fatalf("scanner(%q) failed: want %q, got %q", in, want, spans)
+		}
+		if count(in) != len(spans) {
+			t.Fatalf("count(%q) failed: want %d, got %d", in, len(spans), count(in))
+		}
+	}
+}
+
+func TestSplitPair(t *testing.T) {
+	var tests = []struct {
+		In, Key, Value string
+		Ok             bool
+	}{
+		{"a=b", "a", "b", true},
+		{"a=", "a", "", true},
+		{"=b", "", "b", true},
+		{"ab", "", "", false},
+		{"a=b=c", "", "", false},
+	}
+	for _, test := range tests {
+		key, value, ok := splitPair(test.In)
+		if key != test.Key || value != test.Value || ok != test.Ok {
+			t.Fatalf("splitPair(%q) failed: got %q %q %t", test.In, key, value, ok)
+		}
+	}
+}
+
+func TestScannerAllocs(t *testing.T) {
+	var in = largeList(1000)
+	var allocs = testing.AllocsPerRun(10, func() {
+		for sc := newScanner(in); ; {
+			if _, ok := sc.next(); !ok {
+				break
+			}
+		}
+	})
+	if allocs != 0 {
+		t.Fatalf("scanner allocated %v times", allocs)
+	}
+}
+
+func TestErrSyntax(t *testing.T) {
+	var m map[string]int
+	if err := StringToInterface("a=1=2", &m); !errors.Is(err, errSyntax) || !errors.Is(err, ErrStrconvex) {
+		t.Fatal("StringToInterface failed.")
+	}
+}
@@ -16,6 +16,9 @@ package strconvex
 import (
 	"errors"
 	"fmt"
+	"reflect"
+	"strconv"
+	"unicode/utf8"
 )
 
 var (
@@ -32,4 +35,128 @@ var (
 	ErrUnsupportedValue = fmt.Errorf("%w: unsupported value", ErrStrconvex)
 	// ErrUnaddressableValue is returned when specified value is unaddressable.
 	ErrUnaddressableValue = fmt.Errorf("%w: unadressable value", ErrStrconvex)
+	// ErrSkipPath is returned by a WalkFunc to skip the contents of a value.
+	ErrSkipPath = fmt.Errorf("%w: skip path", ErrStrconvex)
+	// ErrInvalidPatch is returned when a JSON Patch document is invalid.
+	ErrInvalidPatch = fmt.Errorf("%w: invalid patch", ErrStrconvex)
+	// ErrTestFailed is returned when a JSON Patch test operation fails.
+	ErrTestFailed = fmt.Errorf("%w: test failed", ErrStrconvex)
+	// ErrUnexportedField is returned when an unexported struct field is
+	// addressed.
+	ErrUnexportedField = fmt.Errorf("%w: unexported field", ErrStrconvex)
 )
+
+// IndexError is returned when an Array or Slice index is out of range.
+// It wraps ErrInvalidPath.
+type IndexError struct {
+	// Index is the index as specified.
+	Index int
+	// Length is the length of the indexed Array or Slice.
+	Length int
+}
+
+// Error implements error on IndexError.
+func (e *IndexError) Error() string {
+	return fmt.Sprintf("%s: index out of range: %d, length %d", ErrInvalidPath, e.Index, e.Length)
+}
+
+// Unwrap returns ErrInvalidPath.
+func (e *IndexError) Unwrap() error { return ErrInvalidPath }
+
+// PathSyntaxError is returned when a path contains a syntax error.
+// It wraps ErrInvalidPath.
+type PathSyntaxError struct {
+	// Path is the path that contains the error.
+	Path string
+	// Offset is the byte offset of the error in Path.
+	Offset int
+	// Char is the offending character or an empty string if the error is at
+	// the end of Path.
+	Char string
+	// Expected describes what was expected at Offset.
+	Expected string
+}
+
+// newPathSyntaxError returns a new *PathSyntaxError at offset in path.
+func newPathSyntaxError(path string, offset int, expected string) *PathSyntaxError {
+	var e = &PathSyntaxError{Path: path, Offset: offset, Expected: expected}
+	if offset < len(path) {
+		var r, size = utf8.DecodeRuneInString(path[offset:])
+		if r == utf8.RuneError {
+			e.Char = path[offset : offset+size]
+		} else {
+			e.Char = string(r)
+		}
+	}
+	return e
+}
+
+// Error implements error on PathSyntaxError.
+func (e *PathSyntaxError) Error() string {
+	var found = "end of path"
+	if e.Char != "" {
+		found = strconv.QuoteRune([]rune(e.Char)[0])
+	}
+	return fmt.Sprintf("%s: syntax error at offset %d: unexpected %s, expected %s",
+		ErrInvalidPath, e.Offset, found, e.Expected)
+}
+
+// Unwrap returns ErrInvalidPath.
+func (e *PathSyntaxError) Unwrap() error { return ErrInvalidPath }
+
+// ResolveError is returned when a syntactically valid path does not resolve
+// against a value. It describes the element that failed to resolve and the
+// value found at the deepest prefix of the path that did. It wraps the error
+// that caused the failure which wraps ErrInvalidPath or, if the element is
+// an unexported struct field, ErrUnexportedField.
+type ResolveError struct {
+	// Path is the path being resolved, in canonical form.
+	Path string
+	// Element is the element of Path that failed to resolve, in canonical
+	// form, e.g. "Name" or "[5]".
+	Element string
+	// Prefix is the part of Path that did resolve, in canonical form. It is
+	// empty if resolution failed on the first element.
+	Prefix string
+	// Kind is the kind of the value found at Prefix.
+	Kind reflect.Kind
+	// Type is the type of the value found at Prefix or nil if there was no
+	// value, i.e. an interface was nil.
+	Type reflect.Type
+	// Suggestion is the name of a field of a struct found at Prefix that is
+	// closest to a missing field name Element, if one is close enough.
+	Suggestion string
+	// Err is the error that caused the failure.
+	Err error
+}
+
+// Error implements error on ResolveError.
+func (e *ResolveError) Error() string {
+	var at = "at root"
+	if e.Prefix != "" {
+		at = fmt.Sprintf("at %q", e.Prefix)
+	}
+	var found = e.Kind.String()
+	if e.Type != nil {
+		found = fmt.Sprintf("%s %s", e.Kind, e.Type)
+	}
+	var s = fmt.Sprintf("%v: cannot resolve %q %s (%s)", e.Err, e.Element, at, found)
+	if e.Suggestion != "" {
+		s += fmt.Sprintf(", did you mean %q?", e.Suggestion)
+	}
+	return s
+}
+
+// Unwrap returns the error that caused the failure.
+func (e *ResolveError) Unwrap() error { return e.Err }
+
+// errPanic is wrapped by errors converted from recovered panics.
+var errPanic = fmt.Errorf("%w: recovered panic", ErrStrconvex)
+
+// recoverError recovers from a panic and sets err to an error describing it
+// that wraps errPanic. It must be called directly by a deferred call.
+func recoverError(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("%w: %v", errPanic, r)
+	}
+}
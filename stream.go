@@ -0,0 +1,189 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// StreamDecoder decodes a list of values or key=value pairs, in the syntax
+// StringToValue uses for Slices and Maps, incrementally from an io.Reader.
+//
+// Input is read one element at a time so memory use is bounded by the size
+// of the largest element, unless all of them are collected with Decode. A
+// single line terminator at the end of the input is ignored.
+type StreamDecoder struct {
+	r      *bufio.Reader
+	limits *Decoder
+	span   []byte
+	index  int
+	done   bool
+	err    error
+}
+
+// NewStreamDecoder returns a new StreamDecoder that reads from r.
+func NewStreamDecoder(r io.Reader) *StreamDecoder {
+	return &StreamDecoder{r: bufio.NewReader(r)}
+}
+
+// Next returns the next element of the list as a string or io.EOF if there
+// are no more elements. Like StringToValue, an empty input is a list with
+// a single empty element.
+func (d *StreamDecoder) Next() (string, error) {
+	if d.err != nil {
+		return "", d.err
+	}
+	if d.done {
+		return "", io.EOF
+	}
+	if d.limits != nil && d.limits.MaxElements > 0 && d.index >= d.limits.MaxElements {
+		d.err = ErrTooManyElements
+		return "", d.err
+	}
+	d.span = d.span[:0]
+	for {
+		var chunk, err = d.r.ReadSlice(',')
+		d.span = append(d.span, chunk...)
+		// Allow for a delimiter or a line terminator past the limit.
+		if d.limits != nil && d.limits.MaxInputLength > 0 && len(d.span) > d.limits.MaxInputLength+2 {
+			d.err = ErrInputTooLong
+			return "", d.err
+		}
+		var element string
+		switch err {
+		case nil:
+			element = string(d.span[:len(d.span)-1])
+		case bufio.ErrBufferFull:
+			continue
+		case io.EOF:
+			d.done = true
+			element = strings.TrimSuffix(strings.TrimSuffix(string(d.span), "\n"), "\r")
+		default:
+			d.err = err
+			return "", err
+		}
+		if d.limits != nil && d.limits.MaxInputLength > 0 && len(element) > d.limits.MaxInputLength {
+			d.err = ErrInputTooLong
+			return "", d.err
+		}
+		d.index++
+		return element, nil
+	}
+}
+
+// Index returns the number of elements read so far.
+func (d *StreamDecoder) Index() int { return d.index }
+
+// Decode decodes all remaining elements into the Slice or Map out points to,
+// replacing its value. Map elements are key=value pairs. Out is left
+// unmodified on error.
+func (d *StreamDecoder) Decode(out interface{}) error {
+	var v = reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return ErrInvalidArgument
+	}
+	v = v.Elem()
+	switch v.Kind() {
+	case reflect.Slice:
+		var t = v.Type().Elem()
+		var dec = decoderFor(t)
+		var list = reflect.MakeSlice(v.Type(), 0, 0)
+		var err = d.each(func(span string) error {
+			var elem = reflect.New(t).Elem()
+			if err := dec(newState(d.limits), span, elem); err != nil {
+				return err
+			}
+			list = reflect.Append(list, elem)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		v.Set(list)
+	case reflect.Map:
+		var m = reflect.MakeMap(v.Type())
+		var err = d.eachPair(v.Type().Key(), v.Type().Elem(), func(key, value reflect.Value) error {
+			m.SetMapIndex(key, value)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		v.Set(m)
+	default:
+		return ErrUnsupportedValue
+	}
+	return nil
+}
+
+// DecodeEach decodes each remaining element of the list read by d into a
+// value of type T and calls f with it. If f returns an error iteration stops
+// and the error is returned.
+func DecodeEach[T any](d *StreamDecoder, f func(T) error) error {
+	var t = reflect.TypeOf((*T)(nil)).Elem()
+	var dec = decoderFor(t)
+	return d.each(func(span string) error {
+		var elem T
+		if err := dec(newState(d.limits), span, reflect.ValueOf(&elem).Elem()); err != nil {
+			return err
+		}
+		return f(elem)
+	})
+}
+
+// DecodeEachPair decodes each remaining key=value pair of the list read by
+// d into a key of type K and a value of type V and calls f with them. If f
+// returns an error iteration stops and the error is returned.
+func DecodeEachPair[K, V any](d *StreamDecoder, f func(K, V) error) error {
+	var kt, vt = reflect.TypeOf((*K)(nil)).Elem(), reflect.TypeOf((*V)(nil)).Elem()
+	return d.eachPair(kt, vt, func(key, value reflect.Value) error {
+		var k, v = *new(K), *new(V)
+		reflect.ValueOf(&k).Elem().Set(key)
+		reflect.ValueOf(&v).Elem().Set(value)
+		return f(k, v)
+	})
+}
+
+// each calls f with each remaining element. Errors from f are returned
+// annotated with the element index.
+func (d *StreamDecoder) each(f func(span string) error) error {
+	for {
+		var span, err = d.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = f(span); err != nil {
+			return fmt.Errorf("element %d: %w", d.index-1, err)
+		}
+	}
+}
+
+// eachPair calls f with each remaining key=value pair decoded to values of
+// types kt and vt.
+func (d *StreamDecoder) eachPair(kt, vt reflect.Type, f func(key, value reflect.Value) error) error {
+	var kdec, vdec = decoderFor(kt), decoderFor(vt)
+	return d.each(func(span string) error {
+		var ks, vs, ok = splitPair(strings.TrimSpace(span))
+		if !ok {
+			return errSyntax
+		}
+		var state = newState(d.limits)
+		var key, value = reflect.New(kt).Elem(), reflect.New(vt).Elem()
+		if err := kdec(state, ks, key); err != nil {
+			return err
+		}
+		if err := vdec(state, vs, value); err != nil {
+			return err
+		}
+		return f(key, value)
+	})
+}
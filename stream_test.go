@@ -0,0 +1,131 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStreamDecoderNext(t *testing.T) {
+	var tests = []struct {
+		In   string
+		Want []string
+	}{
+		{"", []string{""}},
+		{"a", []string{"a"}},
+		{"a,b,", []string{"a", "b", ""}},
+		{"a, b\n", []string{"a", " b"}},
+		{"a,b\r\n", []string{"a", "b"}},
+	}
+	for _, test := range tests {
+		var d = NewStreamDecoder(strings.NewReader(test.In))
+		var got []string
+		for {
+			var span, err = d.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, span)
+		}
+		if !reflect.DeepEqual(got, test.Want) || d.Index() != len(test.Want) {
+			t.Fatalf("Next(%q) failed: want %q, got %q", test.In, test.Want, got)
+		}
+	}
+}
+
+func TestStreamDecoderLongElement(t *testing.T) {
+	var long = strings.Repeat("x", 3*4096)
+	var d = NewStreamDecoder(bufio.NewReaderSize(strings.NewReader(long+",y"), 16))
+	if span, err := d.Next(); err != nil || span != long {
+		t.Fatal("Next failed.", err)
+	}
+	if span, err := d.Next(); err != nil || span != "y" {
+		t.Fatal("Next failed.", err)
+	}
+}
+
+func TestStreamDecoderDecode(t *testing.T) {
+	var ids []int
+	if err := NewStreamDecoder(strings.NewReader(largeList(10000) + "\n")).Decode(&ids); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 10000 || ids[9999] != 9999 {
+		t.Fatal("Decode(slice) failed.")
+	}
+	var m map[int]uint
+	if err := NewStreamDecoder(strings.NewReader(largeMap(1000))).Decode(&m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 1000 || m[999] != 1998 {
+		t.Fatal("Decode(map) failed.")
+	}
+	ids = []int{1}
+	var err = NewStreamDecoder(strings.NewReader("1,2,x,4")).Decode(&ids)
+	if err == nil || !strings.HasPrefix(err.Error(), "element 2: ") || len(ids) != 1 {
+		t.Fatal("Decode failed.", err)
+	}
+	if err = NewStreamDecoder(strings.NewReader("a=1,b")).Decode(&m); err == nil {
+		t.Fatal("Decode failed.")
+	}
+	if err = NewStreamDecoder(strings.NewReader("1")).Decode(ids); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal("Decode failed.", err)
+	}
+	var s string
+	if err = NewStreamDecoder(strings.NewReader("1")).Decode(&s); !errors.Is(err, ErrUnsupportedValue) {
+		t.Fatal("Decode failed.", err)
+	}
+}
+
+func TestDecodeEach(t *testing.T) {
+	var d = NewStreamDecoder(strings.NewReader("1,2,3,4"))
+	var sum int
+	if err := DecodeEach(d, func(i int) error {
+		sum += i
+		return nil
+	}); err != nil || sum != 10 {
+		t.Fatal("DecodeEach failed.", err)
+	}
+	var stop = errors.New("stop")
+	d = NewStreamDecoder(strings.NewReader("1,2,3,4"))
+	if err := DecodeEach(d, func(i int) error {
+		if i == 2 {
+			return stop
+		}
+		return nil
+	}); !errors.Is(err, stop) {
+		t.Fatal("DecodeEach failed.", err)
+	}
+	if s, err := d.Next(); err != nil || s != "3" {
+		t.Fatal("DecodeEach failed.", err)
+	}
+	var pairs = map[string]int{}
+	d = NewStreamDecoder(strings.NewReader("a=1, b=2"))
+	if err := DecodeEachPair(d, func(k string, v int) error {
+		pairs[k] = v
+		return nil
+	}); err != nil || pairs["b"] != 2 {
+		t.Fatal("DecodeEachPair failed.", err)
+	}
+}
+
+func BenchmarkStreamDecoder(b *testing.B) {
+	var in = largeList(10000)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(in)))
+	for i := 0; i < b.N; i++ {
+		var d = NewStreamDecoder(strings.NewReader(in))
+		if err := DecodeEach(d, func(int) error { return nil }); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -14,17 +14,15 @@
 package strconvex
 
 import (
-	"encoding"
-	"errors"
 	"reflect"
 	"strconv"
-	"strings"
 )
 
 // StringToInterface converts string in to out which must be a pointer to a Go
 // value conversion compatible to data contained in string. See StringToValue
 // for details.
-func StringToInterface(in string, out interface{}) error {
+func StringToInterface(in string, out interface{}) (err error) {
+	defer recoverError(&err)
 	if out == nil {
 		return ErrInvalidArgument
 	}
@@ -32,7 +30,11 @@ func StringToInterface(in string, out interface{}) error {
 }
 
 func stringToInterface(in string, out interface{}) error {
-	return stringToValue(in, reflect.Indirect(reflect.ValueOf(out)))
+	var v = reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return ErrInvalidArgument
+	}
+	return stringToValue(in, v.Elem())
 }
 
 // StringToValue converts string in to out reflect.Value whose type must be
@@ -54,54 +56,26 @@ func stringToInterface(in string, out interface{}) error {
 //
 // Invalid syntax for compound values, or Chans and Func values as input values
 // will result in an error.
-func StringToValue(in string, out reflect.Value) error {
+func StringToValue(in string, out reflect.Value) (err error) {
+	defer recoverError(&err)
 	if !out.IsValid() {
 		return ErrInvalidValue
 	}
+	if !decodable(out) {
+		return ErrUnaddressableValue
+	}
 	return stringToValue(in, out)
 }
 
+// stringToValue converts in to out using the cached decoding plan of the
+// type of out.
 func stringToValue(in string, out reflect.Value) error {
-	bum, ok := out.Interface().(encoding.TextUnmarshaler)
-	if ok {
-		if err := bum.UnmarshalText([]byte(in)); err != nil {
-			return err
-		}
-		return nil
-	}
-	switch out.Kind() {
-	case reflect.Bool:
-		return stringToBoolValue(in, out)
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return stringToIntValue(in, out)
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		return stringToUintValue(in, out)
-	case reflect.Float32:
-		return stringToFloat32Value(in, out)
-	case reflect.Float64:
-		return stringToFloat64Value(in, out)
-	case reflect.Complex64:
-		return stringToComplex64Value(in, out)
-	case reflect.Complex128:
-		return stringToComplex128Value(in, out)
-	case reflect.String:
-		return stringToStringValue(in, out)
-	case reflect.Array:
-		return stringToArrayValue(in, out)
-	case reflect.Slice:
-		return stringToSliceValue(in, out)
-	case reflect.Map:
-		return stringToMapValue(in, out)
-	case reflect.Struct:
-		return stringToStructValue(in, out)
-	case reflect.Ptr:
-		return stringToPointerValue(in, out)
-	}
-	return ErrUnsupportedValue
+	return decoderFor(out.Type())(nil, in, out)
 }
 
 // StringToBoolValue converts a string to a bool.
-func StringToBoolValue(in string, out reflect.Value) error {
+func StringToBoolValue(in string, out reflect.Value) (err error) {
+	defer recoverError(&err)
 	if !out.IsValid() {
 		return ErrInvalidValue
 	}
@@ -117,12 +91,14 @@ func stringToBoolValue(in string, out reflect.Value) error {
 	if err != nil {
 		return err
 	}
-	out.Set(reflect.ValueOf(b))
+	out.SetBool(b)
 	return nil
 }
 
-// StringToIntValue converts a string to a int of any width.
-func StringToIntValue(in string, out reflect.Value) error {
+// StringToIntValue converts a string to a int of any width. See
+// RegisterEnum for ints converted from names.
+func StringToIntValue(in string, out reflect.Value) (err error) {
+	defer recoverError(&err)
 	if !out.IsValid() {
 		return ErrInvalidValue
 	}
@@ -132,18 +108,30 @@ func StringToIntValue(in string, out reflect.Value) error {
 	return stringToIntValue(in, out)
 }
 
-// StringToIntValue is the implementation of stringToIntValue.
+// stringToIntValue converts a name of a registered enum value or a number
+// to an int.
 func stringToIntValue(in string, out reflect.Value) error {
-	n, err := strconv.ParseInt(in, 10, 64)
+	if e := enumFor(out.Type()); e != nil {
+		return e.decode(in, out)
+	}
+	return parseInt(in, out)
+}
+
+// parseInt converts a number to an int. Numbers that do not fit in out
+// result in a range error.
+func parseInt(in string, out reflect.Value) error {
+	n, err := strconv.ParseInt(in, 10, out.Type().Bits())
 	if err != nil {
 		return err
 	}
-	out.Set(reflect.ValueOf(n).Convert(out.Type()))
+	out.SetInt(n)
 	return nil
 }
 
-// StringToUintValue converts a string to an uint of any width.
-func StringToUintValue(in string, out reflect.Value) error {
+// StringToUintValue converts a string to an uint of any width. See
+// RegisterEnum for uints converted from names.
+func StringToUintValue(in string, out reflect.Value) (err error) {
+	defer recoverError(&err)
 	if !out.IsValid() {
 		return ErrInvalidValue
 	}
@@ -153,17 +141,29 @@ func StringToUintValue(in string, out reflect.Value) error {
 	return stringToUintValue(in, out)
 }
 
+// stringToUintValue converts a name of a registered enum value or a number
+// to an uint.
 func stringToUintValue(in string, out reflect.Value) error {
-	n, err := strconv.ParseUint(in, 10, 64)
+	if e := enumFor(out.Type()); e != nil {
+		return e.decode(in, out)
+	}
+	return parseUint(in, out)
+}
+
+// parseUint converts a number to an uint. Numbers that do not fit in out
+// result in a range error.
+func parseUint(in string, out reflect.Value) error {
+	n, err := strconv.ParseUint(in, 10, out.Type().Bits())
 	if err != nil {
 		return err
 	}
-	out.Set(reflect.ValueOf(n).Convert(out.Type()))
+	out.SetUint(n)
 	return nil
 }
 
 // StringToFloat32Value converts a string to a float32.
-func StringToFloat32Value(in string, out reflect.Value) error {
+func StringToFloat32Value(in string, out reflect.Value) (err error) {
+	defer recoverError(&err)
 	if !out.IsValid() {
 		return ErrInvalidValue
 	}
@@ -178,12 +178,13 @@ func stringToFloat32Value(in string, out reflect.Value) error {
 	if err != nil {
 		return err
 	}
-	out.Set(reflect.ValueOf(n).Convert(out.Type()))
+	out.SetFloat(n)
 	return nil
 }
 
 // StringToFloat64Value converts a string to a float64.
-func StringToFloat64Value(in string, out reflect.Value) error {
+func StringToFloat64Value(in string, out reflect.Value) (err error) {
+	defer recoverError(&err)
 	if !out.IsValid() {
 		return ErrInvalidValue
 	}
@@ -198,12 +199,13 @@ func stringToFloat64Value(in string, out reflect.Value) error {
 	if err != nil {
 		return err
 	}
-	out.Set(reflect.ValueOf(n).Convert(out.Type()))
+	out.SetFloat(n)
 	return nil
 }
 
 // StringToComplex64Value converts a string to a complex64.
-func StringToComplex64Value(in string, out reflect.Value) error {
+func StringToComplex64Value(in string, out reflect.Value) (err error) {
+	defer recoverError(&err)
 	if !out.IsValid() {
 		return ErrInvalidValue
 	}
@@ -218,12 +220,13 @@ func stringToComplex64Value(in string, out reflect.Value) error {
 	if err != nil {
 		return err
 	}
-	out.Set(reflect.ValueOf(n).Convert(out.Type()))
+	out.SetComplex(n)
 	return nil
 }
 
 // StringToComplex128Value converts a string to a complex128.
-func StringToComplex128Value(in string, out reflect.Value) error {
+func StringToComplex128Value(in string, out reflect.Value) (err error) {
+	defer recoverError(&err)
 	if !out.IsValid() {
 		return ErrInvalidValue
 	}
@@ -238,12 +241,13 @@ func stringToComplex128Value(in string, out reflect.Value) error {
 	if err != nil {
 		return err
 	}
-	out.Set(reflect.ValueOf(n).Convert(out.Type()))
+	out.SetComplex(n)
 	return nil
 }
 
 // StringToStringValue converts a string to a string.
-func StringToStringValue(in string, out reflect.Value) error {
+func StringToStringValue(in string, out reflect.Value) (err error) {
+	defer recoverError(&err)
 	if !out.IsValid() {
 		return ErrInvalidValue
 	}
@@ -254,14 +258,15 @@ func StringToStringValue(in string, out reflect.Value) error {
 }
 
 func stringToStringValue(in string, out reflect.Value) error {
-	out.Set(reflect.ValueOf(in))
+	out.SetString(in)
 	return nil
 }
 
 // StringToArrayValue converts a string to an array.
 // String is of the form "elem1,elem2,elemN".
 // Elements must be simple values.
-func StringToArrayValue(in string, out reflect.Value) error {
+func StringToArrayValue(in string, out reflect.Value) (err error) {
+	defer recoverError(&err)
 	if !out.IsValid() {
 		return ErrInvalidValue
 	}
@@ -272,21 +277,14 @@ func StringToArrayValue(in string, out reflect.Value) error {
 }
 
 func stringToArrayValue(in string, out reflect.Value) error {
-	v := reflect.Indirect(reflect.New(out.Type()))
-	a := strings.Split(in, ",")
-	for i, l := 0, out.Len(); i < l && i < len(a); i++ {
-		if err := StringToValue(strings.TrimSpace(a[i]), v.Index(i)); err != nil {
-			return err
-		}
-	}
-	out.Set(v)
-	return nil
+	return decodeArray(nil, in, out, decoderFor(out.Type().Elem()))
 }
 
 // StringToSliceValue converts a string to a slice.
 // String is of the form "elem1,elem2,elemN".
 // Elements must be simple values.
-func StringToSliceValue(in string, out reflect.Value) error {
+func StringToSliceValue(in string, out reflect.Value) (err error) {
+	defer recoverError(&err)
 	if !out.IsValid() {
 		return ErrInvalidValue
 	}
@@ -297,21 +295,14 @@ func StringToSliceValue(in string, out reflect.Value) error {
 }
 
 func stringToSliceValue(in string, out reflect.Value) error {
-	a := strings.Split(in, ",")
-	parsedval := reflect.MakeSlice(reflect.SliceOf(out.Type().Elem()), len(a), len(a))
-	for i := 0; i < len(a); i++ {
-		if err := StringToValue(a[i], parsedval.Index(i)); err != nil {
-			return err
-		}
-	}
-	out.Set(parsedval)
-	return nil
+	return decodeSlice(nil, in, out, decoderFor(out.Type().Elem()))
 }
 
 // StringToMapValue converts a string to a map.
 // String is of the form: "key1=val1,key2=val2,keyN=valN".
 // Elements must be simple values.
-func StringToMapValue(in string, out reflect.Value) error {
+func StringToMapValue(in string, out reflect.Value) (err error) {
+	defer recoverError(&err)
 	if !out.IsValid() {
 		return ErrInvalidValue
 	}
@@ -322,32 +313,14 @@ func StringToMapValue(in string, out reflect.Value) error {
 }
 
 func stringToMapValue(in string, out reflect.Value) error {
-	var maptype = reflect.MapOf(out.Type().Key(), out.Type().Elem())
-	var newmap = reflect.MakeMap(maptype)
-	var key, val reflect.Value
-	var pair []string
-	for _, s := range strings.Split(in, ",") {
-		if pair = strings.Split(strings.TrimSpace(s), "="); len(pair) != 2 {
-			return errors.New("strconvex: syntax error")
-		}
-		key = reflect.Indirect(reflect.New(maptype.Key()))
-		if err := StringToValue(pair[0], key); err != nil {
-			return err
-		}
-		val = reflect.Indirect(reflect.New(maptype.Elem()))
-		if err := StringToValue(pair[1], val); err != nil {
-			return err
-		}
-		newmap.SetMapIndex(key, val)
-	}
-	out.Set(newmap)
-	return nil
+	return decodeMap(nil, in, out, decoderFor(out.Type().Key()), decoderFor(out.Type().Elem()))
 }
 
 // StringToStructValue converts a string to a struct.
 // String is of the form: "{field1=value1,field2=value2,fieldN=valueN}"
 // Fields must be simple values.
-func StringToStructValue(in string, out reflect.Value) error {
+func StringToStructValue(in string, out reflect.Value) (err error) {
+	defer recoverError(&err)
 	if !out.IsValid() {
 		return ErrInvalidValue
 	}
@@ -358,27 +331,12 @@ func StringToStructValue(in string, out reflect.Value) error {
 }
 
 func stringToStructValue(in string, out reflect.Value) error {
-	var pair []string
-	var field reflect.Value
-	var val reflect.Value
-	for _, s := range strings.Split(strings.TrimPrefix(strings.TrimSuffix(in, "}"), "{"), ",") {
-		if pair = strings.Split(strings.TrimSpace(s), "="); len(pair) != 2 {
-			return errors.New("strconvex: syntax error")
-		}
-		if field = out.FieldByName(pair[0]); !field.IsValid() {
-			return errors.New("strconvex: field not found")
-		}
-		val = reflect.Indirect(reflect.New(field.Type()))
-		if err := StringToValue(pair[1], val); err != nil {
-			return err
-		}
-		field.Set(val)
-	}
-	return nil
+	return decodeStruct(nil, in, out, structPlanFor(out.Type()))
 }
 
 // StringToPointerValue converts a string to a pointer.
-func StringToPointerValue(in string, out reflect.Value) error {
+func StringToPointerValue(in string, out reflect.Value) (err error) {
+	defer recoverError(&err)
 	if !out.IsValid() {
 		return ErrInvalidValue
 	}
@@ -389,10 +347,5 @@ func StringToPointerValue(in string, out reflect.Value) error {
 }
 
 func stringToPointerValue(in string, out reflect.Value) error {
-	nv := reflect.New(out.Type().Elem())
-	if err := StringToValue(in, reflect.Indirect(nv)); err != nil {
-		return err
-	}
-	out.Set(nv)
-	return nil
+	return decodePointer(nil, in, out, decoderFor(out.Type().Elem()))
 }
@@ -6,7 +6,11 @@ package strconvex
 
 import (
 	"bytes"
+	"encoding"
+	"errors"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -382,3 +386,391 @@ func BenchmarkStringToDeepPointerValue(b *testing.B) {
 		StringToValue(in, out)
 	}
 }
+
+type Row struct {
+	ID      int
+	Name    string
+	Enabled bool
+	Score   float64
+	Tags    []string
+	Next    *Row
+}
+
+func TestDecoderPlanCache(t *testing.T) {
+	var row Row
+	if err := StringToInterface("{ID=1, Name=foo, Score=1.5}", &row); err != nil {
+		t.Fatal(err)
+	}
+	if row.ID != 1 || row.Name != "foo" || row.Score != 1.5 {
+		t.Fatal("StringToInterface(struct) failed")
+	}
+	if _, ok := decoders.Load(reflect.TypeOf(row)); !ok {
+		t.Fatal("decoder plan not cached")
+	}
+	var plan = structPlanFor(reflect.TypeOf(row))
+	if a, _ := plan.field("Name", false); a == nil {
+		t.Fatal("field plan not found")
+	} else if b, _ := plan.field("Name", false); a != b {
+		t.Fatal("field plan not cached")
+	}
+	if field, err := plan.field("Missing", false); field != nil || err == nil {
+		t.Fatal("field plan for a missing field")
+	}
+	if err := StringToInterface("{ }", &row); err != nil || row.ID != 1 {
+		t.Fatal("StringToInterface(struct) failed to decode an empty struct", err)
+	}
+	if err := StringToInterface("{Missing=1}", &row); !errors.Is(err, errFieldNotFound) || !errors.Is(err, ErrStrconvex) {
+		t.Fatal("StringToInterface(struct) failed to detect a missing field")
+	}
+	var next *Row
+	if err := StringToInterface("{ID=2}", &next); err != nil || next.ID != 2 {
+		t.Fatal("StringToInterface(recursive) failed", err)
+	}
+	var done = make(chan error)
+	for i := 0; i < 8; i++ {
+		go func() {
+			var row Row
+			done <- StringToInterface("{ID=3, Enabled=true}", &row)
+		}()
+	}
+	for i := 0; i < 8; i++ {
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+const benchmarkRow = "{ID=42, Name=foo, Enabled=true, Score=3.14}"
+
+func BenchmarkStringToValueRow(b *testing.B) {
+	var row Row
+	out := reflect.Indirect(reflect.ValueOf(&row))
+	for i := 0; i < b.N; i++ {
+		if err := StringToValue(benchmarkRow, out); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkStringToValueRowColdPlan decodes the same input as
+// BenchmarkStringToValueRow with an empty struct plan on every call, which
+// measures resolving fields on first use of a struct type.
+func BenchmarkStringToValueRowColdPlan(b *testing.B) {
+	var row Row
+	out := reflect.Indirect(reflect.ValueOf(&row))
+	for i := 0; i < b.N; i++ {
+		if err := decodeStruct(nil, benchmarkRow, out, &structPlan{t: out.Type()}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkStringToValueRowKindSwitch decodes the same input as
+// BenchmarkStringToValueRow with stringToValueKindSwitch, the decoding path
+// used before plans were cached.
+func BenchmarkStringToValueRowKindSwitch(b *testing.B) {
+	var row Row
+	out := reflect.Indirect(reflect.ValueOf(&row))
+	for i := 0; i < b.N; i++ {
+		if err := stringToValueKindSwitch(benchmarkRow, out); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestStringToValueKindSwitch(t *testing.T) {
+	var a, b Row
+	if err := stringToValueKindSwitch(benchmarkRow, reflect.ValueOf(&a).Elem()); err != nil {
+		t.Fatal(err)
+	}
+	if err := StringToValue(benchmarkRow, reflect.ValueOf(&b).Elem()); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("StringToValueKindSwitch failed.")
+	}
+}
+
+// stringToValueKindSwitch is a copy of the decoder that switched on the Kind
+// of out and looked struct fields up by name on every call, limited to the
+// kinds of Row. It is kept to benchmark the cached plans against.
+func stringToValueKindSwitch(in string, out reflect.Value) error {
+	bum, ok := out.Interface().(encoding.TextUnmarshaler)
+	if !ok && out.CanAddr() {
+		bum, ok = out.Addr().Interface().(encoding.TextUnmarshaler)
+	}
+	if ok {
+		return bum.UnmarshalText([]byte(in))
+	}
+	switch out.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(in)
+		if err != nil {
+			return err
+		}
+		out.Set(reflect.ValueOf(b))
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(in, 10, 64)
+		if err != nil {
+			return err
+		}
+		out.Set(reflect.ValueOf(n).Convert(out.Type()))
+		return nil
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(in, 64)
+		if err != nil {
+			return err
+		}
+		out.Set(reflect.ValueOf(n).Convert(out.Type()))
+		return nil
+	case reflect.String:
+		out.Set(reflect.ValueOf(in))
+		return nil
+	case reflect.Slice:
+		a := strings.Split(in, ",")
+		parsedval := reflect.MakeSlice(reflect.SliceOf(out.Type().Elem()), len(a), len(a))
+		for i := 0; i < len(a); i++ {
+			if err := stringToValueKindSwitch(a[i], parsedval.Index(i)); err != nil {
+				return err
+			}
+		}
+		out.Set(parsedval)
+		return nil
+	case reflect.Struct:
+		var pair []string
+		var field reflect.Value
+		var val reflect.Value
+		for _, s := range strings.Split(strings.TrimPrefix(strings.TrimSuffix(in, "}"), "{"), ",") {
+			if pair = strings.Split(strings.TrimSpace(s), "="); len(pair) != 2 {
+				return errors.New("strconvex: syntax error")
+			}
+			if field = out.FieldByName(pair[0]); !field.IsValid() {
+				return errors.New("strconvex: field not found")
+			}
+			val = reflect.Indirect(reflect.New(field.Type()))
+			if err := stringToValueKindSwitch(pair[1], val); err != nil {
+				return err
+			}
+			field.Set(val)
+		}
+		return nil
+	case reflect.Ptr:
+		nv := reflect.New(out.Type().Elem())
+		if err := stringToValueKindSwitch(in, reflect.Indirect(nv)); err != nil {
+			return err
+		}
+		out.Set(nv)
+		return nil
+	}
+	return ErrUnsupportedValue
+}
+
+// largeList returns a list of n integers in the format of a Slice.
+func largeList(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.Itoa(i))
+	}
+	return b.String()
+}
+
+// largeMap returns a list of n key=value pairs in the format of a Map.
+func largeMap(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.Itoa(i))
+		b.WriteByte('=')
+		b.WriteString(strconv.Itoa(i * 2))
+	}
+	return b.String()
+}
+
+func TestStringToValueLarge(t *testing.T) {
+	var ids []int
+	if err := StringToInterface(largeList(10000), &ids); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 10000 || ids[9999] != 9999 {
+		t.Fatal("StringToValue(large slice) failed")
+	}
+	var m map[int]uint
+	if err := StringToInterface(largeMap(10000), &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 10000 || m[9999] != 19998 {
+		t.Fatal("StringToValue(large map) failed")
+	}
+}
+
+func BenchmarkStringToValueLargeSlice(b *testing.B) {
+	in := largeList(10000)
+	var val []int
+	out := reflect.Indirect(reflect.ValueOf(&val))
+	b.ReportAllocs()
+	b.SetBytes(int64(len(in)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := StringToValue(in, out); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStringToValueLargeMap(b *testing.B) {
+	in := largeMap(10000)
+	var val map[int]uint
+	out := reflect.Indirect(reflect.ValueOf(&val))
+	b.ReportAllocs()
+	b.SetBytes(int64(len(in)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := StringToValue(in, out); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStringToValueRowAllocs(b *testing.B) {
+	var row Row
+	out := reflect.Indirect(reflect.ValueOf(&row))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := StringToValue(benchmarkRow, out); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestStringToValueEmbedded(t *testing.T) {
+	var data Outer
+	if err := StringToInterface("{Name=foo, Port=1, Shared=2}", &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Inner == nil || data.Name != "foo" || data.Port != 1 || data.Shared != 2 {
+		t.Fatal("StringToValue(embedded) failed")
+	}
+	if err := StringToInterface("{hidden=1}", &data); !errors.Is(err, ErrUnexportedField) {
+		t.Fatal("StringToValue(unexported) failed", err)
+	}
+	if err := StringToInterface("{Secret=1}", &OuterHidden{}); !errors.Is(err, ErrUnexportedField) {
+		t.Fatal("StringToValue(unexported embedded) failed", err)
+	}
+	if err := StringToInterface("{Inner.Port=2}", &data); err == nil {
+		t.Fatal("StringToValue(qualified) failed")
+	}
+	var d = &Decoder{EmbeddedByTypeName: true}
+	if err := d.DecodeString("{Inner.Port=2, Port=3}", &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Inner.Port != 2 || data.Port != 3 {
+		t.Fatal("DecodeString(qualified) failed")
+	}
+	if err := StringToInterface("{Inner.Port=4}", &data); err == nil {
+		t.Fatal("StringToValue(qualified) failed")
+	}
+	if err := d.DecodeString("{Port.Inner=2}", &data); err == nil {
+		t.Fatal("DecodeString(qualified) failed")
+	}
+	if err := d.DecodeString("{inner.Secret=5}", &data); err != nil || data.Secret != 5 {
+		t.Fatal("DecodeString(qualified) failed", err)
+	}
+}
+
+type fuzzTarget struct {
+	Bool    bool
+	Int8    int8
+	Uint    uint
+	Float   float32
+	Complex complex64
+	String  string
+	Time    time.Time
+	TimePtr *time.Time
+	Ptr     ***int
+	Array   [3]int
+	Slice   []*string
+	Map     map[int8]bool
+	Struct  Child
+	Outer   Outer
+	Any     interface{}
+	Chan    chan int
+	hidden  int
+}
+
+// fuzzTargets returns pointers to new values of types StringToInterface is
+// fuzzed with.
+func fuzzTargets() []interface{} {
+	return []interface{}{
+		new(bool), new(int), new(int8), new(uint64), new(uintptr), new(float32),
+		new(float64), new(complex128), new(string), new(time.Time),
+		new(*time.Time), new(time.Duration), new(***int), new([3]int),
+		new([0]int), new([]int), new([]*string), new([]time.Time),
+		new(map[string]int), new(map[int8]*bool), new(map[time.Duration]string),
+		new(Child), new(Outer), new(OuterHidden), new(fuzzTarget), new(*Outer),
+		new(interface{}), new(chan int), new(func()), new([]interface{}),
+	}
+}
+
+func FuzzStringToValue(f *testing.F) {
+	for _, seed := range []string{
+		"", "0", "-1", "true", "1.5", "1+2i", "foo", "a,b,c", "1,2,3", "k=v",
+		"a=1,b=2", "{Int=1}", "{Name=foo, Port=1}", "{hidden=1}", "{Inner.Port=1}",
+		"2006-01-02T15:04:05Z", "=", ",", "{", "}", "{=}", "{,}",
+	} {
+		f.Add(seed)
+	}
+	var limited = &Decoder{
+		MaxInputLength:     64,
+		MaxElements:        4,
+		MaxDepth:           2,
+		MaxAllocations:     8,
+		MaxStringLength:    8,
+		EmbeddedByTypeName: true,
+	}
+	f.Fuzz(func(t *testing.T, in string) {
+		for _, target := range fuzzTargets() {
+			if err := StringToInterface(in, target); errors.Is(err, errPanic) {
+				t.Fatalf("StringToInterface(%q, %T) panicked: %v", in, target, err)
+			}
+			if err := StringToValue(in, reflect.ValueOf(target)); errors.Is(err, errPanic) {
+				t.Fatalf("StringToValue(%q, %T) panicked: %v", in, target, err)
+			}
+			if err := limited.DecodeString(in, target); errors.Is(err, errPanic) {
+				t.Fatalf("DecodeString(%q, %T) panicked: %v", in, target, err)
+			}
+		}
+	})
+}
+
+func TestPanicFree(t *testing.T) {
+	if err := StringToInterface("1", (*int)(nil)); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal("StringToInterface(nil pointer) failed", err)
+	}
+	if err := StringToInterface("1", 1); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal("StringToInterface(non-pointer) failed", err)
+	}
+	if err := StringToValue("1", reflect.ValueOf(1)); !errors.Is(err, ErrUnaddressableValue) {
+		t.Fatal("StringToValue(unaddressable) failed", err)
+	}
+	var now = time.Now().UTC().Truncate(time.Second)
+	var tp *time.Time
+	if err := StringToInterface(now.Format(time.RFC3339), &tp); err != nil || tp == nil || !tp.Equal(now) {
+		t.Fatal("StringToInterface(nil TextUnmarshaler) failed", err)
+	}
+	if err := StringToValue(now.Format(time.RFC3339), reflect.ValueOf((*time.Time)(nil))); !errors.Is(err, ErrUnaddressableValue) {
+		t.Fatal("StringToValue(nil TextUnmarshaler) failed", err)
+	}
+	var err = func() (err error) {
+		defer recoverError(&err)
+		panic("boom")
+	}()
+	if !errors.Is(err, ErrStrconvex) || !errors.Is(err, errPanic) {
+		t.Fatal("recoverError failed", err)
+	}
+}
@@ -0,0 +1,569 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ValidateTag is the struct tag key of field validation rules.
+const ValidateTag = "validate"
+
+// ErrValidation is returned when a value fails validation.
+var ErrValidation = fmt.Errorf("%w: validation failed", ErrStrconvex)
+
+// ValidationError describes a value that failed a validation rule.
+// It wraps ErrValidation.
+type ValidationError struct {
+	// Path is the path of the value.
+	Path string
+	// Rule is the rule that failed, e.g. "max=65535".
+	Rule string
+	// Value is the value that failed the rule, converted to a string.
+	Value string
+}
+
+// Error implements error on ValidationError.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s: %s: %q", ErrValidation, e.Path, e.Rule, e.Value)
+}
+
+// Unwrap returns ErrValidation.
+func (e *ValidationError) Unwrap() error { return ErrValidation }
+
+// ValidationErrors is a list of validation failures. It wraps ErrValidation.
+type ValidationErrors []*ValidationError
+
+// Error implements error on ValidationErrors.
+func (e ValidationErrors) Error() string {
+	var a = make([]string, 0, len(e))
+	for _, err := range e {
+		a = append(a, fmt.Sprintf("%s: %s: %q", err.Path, err.Rule, err.Value))
+	}
+	return fmt.Sprintf("%s: %s", ErrValidation, strings.Join(a, "; "))
+}
+
+// Unwrap returns ErrValidation.
+func (e ValidationErrors) Unwrap() error { return ErrValidation }
+
+// Validate validates the value ptr points to against the rules in validate
+// tags of its struct fields and of the fields of structs nested in it,
+// including those in Arrays, Slices, Maps, pointed to by pointers and
+// embedded, exported or not. Fields promoted from unexported embedded structs
+// are reported by their promoted paths. Pointers to values that contain
+// them, i.e. cycles, are not descended into.
+//
+// Rules are delimited by a comma and are applied to the field value:
+//
+//	required     the value is not the zero value of its type; pointers,
+//	             Slices and Maps are not nil
+//	nonzero      the value, with pointers dereferenced, is not the zero
+//	             value of its type and Arrays, Slices, Maps and strings are
+//	             not empty
+//	min=n        numbers are at least n, lengths of strings (in runes),
+//	             Arrays, Slices and Maps are at least n
+//	max=n        like min but at most n
+//	len=n        lengths of strings (in runes), Arrays, Slices and Maps are
+//	             exactly n
+//	oneof=a b c  the value converted by ValueToString is one of the space
+//	             delimited values
+//	regexp=re    the value converted by ValueToString matches the regular
+//	             expression re which extends to the end of the tag
+//
+// Rules other than required are applied to the value pointers point to and
+// skipped for nil pointers. For example:
+//
+//	Port  int    `validate:"min=1,max=65535"`
+//	Level string `validate:"required,oneof=debug info warn"`
+//
+// If any values fail validation ValidationErrors listing all failures in
+// path order is returned. An invalid rule results in an error wrapping
+// ErrInvalidArgument.
+//
+// Set, SetPointer and CompiledPath.Set validate the values they set.
+// SetValue, Unflatten, Apply and ApplyPatch do not; call Validate after them
+// to validate their results.
+func Validate(ptr interface{}) error {
+	var v = reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return ErrInvalidArgument
+	}
+	var errs ValidationErrors
+	if err := validate(v, "", nil, &errs, nil); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validate validates v found at path against rules and then its contents
+// against their rules, adding failures to errs. Seen holds the pointers,
+// Slices and Maps being descended into and may be nil.
+func validate(v reflect.Value, path string, rules []rule, errs *ValidationErrors, seen map[ptrKey]bool) error {
+	for _, r := range rules {
+		var ok, err = r.check(v)
+		if err != nil {
+			return fmt.Errorf("%w: %s: rule %s: %v", ErrInvalidArgument, path, r.text, err)
+		}
+		if !ok {
+			var s, _ = valueToString(v)
+			*errs = append(*errs, &ValidationError{Path: path, Rule: r.text, Value: s})
+		}
+	}
+	if key, ok := pointerKey(v); ok {
+		if seen[key] {
+			return nil
+		}
+		if seen == nil {
+			seen = make(map[ptrKey]bool)
+		}
+		seen[key] = true
+		defer delete(seen, key)
+	}
+	if v = indirect(v); !v.IsValid() {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		var fields, err = structRules(v.Type())
+		if err != nil {
+			return err
+		}
+		for _, field := range fields {
+			var fpath = path
+			if !field.promoted {
+				fpath = joinName(path, field.name)
+			}
+			if err = validate(v.Field(field.index), fpath, field.rules, errs, seen); err != nil {
+				return err
+			}
+		}
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := validate(v.Index(i), joinKey(path, strconv.Itoa(i)), nil, errs, seen); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for _, key := range sortedKeys(v) {
+			var s, err = valueToString(key)
+			if err != nil {
+				return err
+			}
+			if err = validate(v.MapIndex(key), joinKey(path, quoteKey(s)), nil, errs, seen); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fieldRulesOf are the validation rules of an exported struct field or of an
+// unexported embedded struct whose fields are promoted.
+type fieldRulesOf struct {
+	index    int
+	name     string
+	promoted bool // paths of fields of an unexported embedded struct omit it
+	rules    []rule
+}
+
+// validationPlans caches []fieldRulesOf per struct type.
+var validationPlans sync.Map // reflect.Type -> []fieldRulesOf
+
+// structRules returns the rules of exported fields and unexported embedded
+// fields of struct type t.
+func structRules(t reflect.Type) ([]fieldRulesOf, error) {
+	if fields, ok := validationPlans.Load(t); ok {
+		return fields.([]fieldRulesOf), nil
+	}
+	var fields []fieldRulesOf
+	for i := 0; i < t.NumField(); i++ {
+		var sf = t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+		var rules, err = fieldRules(sf)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, fieldRulesOf{index: i, name: sf.Name, promoted: sf.PkgPath != "", rules: rules})
+	}
+	validationPlans.Store(t, fields)
+	return fields, nil
+}
+
+// fieldRules returns the rules in the validate tag of struct field sf.
+func fieldRules(sf reflect.StructField) ([]rule, error) {
+	var tag, ok = sf.Tag.Lookup(ValidateTag)
+	if !ok {
+		return nil, nil
+	}
+	var rules, err = parseRules(tag)
+	if err != nil {
+		return nil, fmt.Errorf("%w: field %s: %v", ErrInvalidArgument, sf.Name, err)
+	}
+	return rules, nil
+}
+
+// rule is a parsed validation rule.
+type rule struct {
+	text string
+	name string
+	arg  string
+	set  []string
+	re   *regexp.Regexp
+}
+
+// parseRules parses a validate tag.
+func parseRules(tag string) ([]rule, error) {
+	var rules []rule
+	for tag != "" {
+		var text = tag
+		if strings.HasPrefix(tag, "regexp=") {
+			tag = ""
+		} else if i := strings.IndexByte(tag, ','); i >= 0 {
+			text, tag = tag[:i], tag[i+1:]
+		} else {
+			tag = ""
+		}
+		var r = rule{text: text, name: text}
+		if i := strings.IndexByte(text, '='); i >= 0 {
+			r.name, r.arg = text[:i], text[i+1:]
+		}
+		switch r.name {
+		case "required", "nonzero":
+			if r.arg != "" {
+				return nil, fmt.Errorf("unexpected argument: %s", text)
+			}
+		case "min", "max", "len":
+			if _, err := strconv.ParseFloat(r.arg, 64); err != nil {
+				return nil, fmt.Errorf("invalid number: %s", text)
+			}
+		case "oneof":
+			r.set = strings.Fields(r.arg)
+		case "regexp":
+			var err error
+			if r.re, err = regexp.Compile(r.arg); err != nil {
+				return nil, fmt.Errorf("invalid regexp: %s: %v", text, err)
+			}
+		default:
+			return nil, fmt.Errorf("unknown rule: %s", text)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// check returns true if v passes the rule or an error if the rule cannot
+// be applied to v.
+func (r *rule) check(v reflect.Value) (bool, error) {
+	if r.name == "required" {
+		return !v.IsZero(), nil
+	}
+	if v = indirect(v); !v.IsValid() {
+		return r.name != "nonzero", nil
+	}
+	switch r.name {
+	case "nonzero":
+		if length, ok := lengthOf(v); ok {
+			return length > 0, nil
+		}
+		return !v.IsZero(), nil
+	case "min", "max":
+		var c, err = compareTo(v, r.arg)
+		if err != nil {
+			return false, err
+		}
+		if r.name == "min" {
+			return c >= 0, nil
+		}
+		return c <= 0, nil
+	case "len":
+		var length, ok = lengthOf(v)
+		if !ok {
+			return false, fmt.Errorf("%s has no length", v.Type())
+		}
+		var n, err = strconv.Atoi(r.arg)
+		if err != nil {
+			return false, err
+		}
+		return length == n, nil
+	}
+	var s, err = valueToString(v)
+	if err != nil {
+		return false, err
+	}
+	if r.name == "regexp" {
+		return r.re.MatchString(s), nil
+	}
+	for _, item := range r.set {
+		if s == item {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// lengthOf returns the length of a string in runes or the length of an
+// Array, Slice or Map and true or false if v has no length.
+func lengthOf(v reflect.Value) (int, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(v.String()), true
+	case reflect.Array, reflect.Slice, reflect.Map:
+		return v.Len(), true
+	}
+	return 0, false
+}
+
+// compareTo compares a number or a length of v to number arg and returns -1,
+// 0 or 1 if it is less than, equal to or greater than arg.
+func compareTo(v reflect.Value, arg string) (int, error) {
+	if length, ok := lengthOf(v); ok {
+		var n, err = strconv.ParseFloat(arg, 64)
+		return compareFloat(float64(length), n), err
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, err := strconv.ParseInt(arg, 10, 64); err == nil {
+			return compareInt(v.Int(), n), nil
+		}
+		var n, err = strconv.ParseFloat(arg, 64)
+		return compareFloat(float64(v.Int()), n), err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n, err := strconv.ParseUint(arg, 10, 64); err == nil {
+			if v.Uint() < n {
+				return -1, nil
+			} else if v.Uint() > n {
+				return 1, nil
+			}
+			return 0, nil
+		}
+		var n, err = strconv.ParseFloat(arg, 64)
+		return compareFloat(float64(v.Uint()), n), err
+	case reflect.Float32, reflect.Float64:
+		var n, err = strconv.ParseFloat(arg, 64)
+		return compareFloat(v.Float(), n), err
+	}
+	return 0, fmt.Errorf("%s is not a number and has no length", v.Type())
+}
+
+// compareInt compares a and b.
+func compareInt(a, b int64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+// compareFloat compares a and b.
+func compareFloat(a, b float64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+// resolveTarget returns the type of the value segs end with when resolved
+// against a value of type t, with pointers dereferenced, or nil if the type
+// cannot be determined because of an interface along the path. If segs end
+// with a struct field it returns the field and the struct type, otherwise a
+// nil struct type.
+func resolveTarget(segs []segment, t reflect.Type) (typ reflect.Type, sf reflect.StructField, owner reflect.Type) {
+	for _, seg := range segs {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		owner = nil
+		switch {
+		case seg.token == NameToken && t.Kind() == reflect.Struct:
+			var ok bool
+			if sf, ok = t.FieldByName(seg.name); !ok {
+				return nil, sf, nil
+			}
+			owner, t = t, sf.Type
+		case seg.token == KeyToken && (t.Kind() == reflect.Array || t.Kind() == reflect.Slice || t.Kind() == reflect.Map):
+			t = t.Elem()
+		default:
+			return nil, reflect.StructField{}, nil
+		}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Interface {
+		return nil, sf, owner
+	}
+	return t, sf, owner
+}
+
+// setTarget is the value a path sets in roots of some type: the struct field
+// the path ends with, if any, its validation rules and how values of its
+// type, if known, are set.
+type setTarget struct {
+	field     reflect.StructField
+	isField   bool
+	rules     []rule
+	err       error        // invalid rules of field
+	typ       reflect.Type // type of the value, nil if not known
+	validated bool         // values of typ are validated
+	inPlace   bool         // values of typ are converted in place
+}
+
+// newSetTarget returns the setTarget of segs in a root of type t.
+func newSetTarget(segs []segment, t reflect.Type) (target setTarget) {
+	var owner reflect.Type
+	target.typ, target.field, owner = resolveTarget(segs, t)
+	if target.isField = owner != nil; target.isField {
+		target.rules, target.err = cachedFieldRules(owner, target.field)
+	}
+	if target.typ != nil {
+		target.validated, target.inPlace = target.check(target.typ)
+	}
+	return
+}
+
+// check returns whether a value of type t set by target is validated and
+// whether it is converted in place because it is not validated and a failed
+// conversion leaves it unchanged.
+func (target *setTarget) check(t reflect.Type) (validated, inPlace bool) {
+	validated = len(target.rules) > 0 || hasRules(t)
+	return validated, !validated && decodesInPlace(t)
+}
+
+// setString returns a modify callback that sets a value found at segs to
+// value converted by StringToValue, or in the encoding of a BytesTag of the
+// target struct field, if the converted value passes validation. Values of
+// types that cannot fail validation are converted in place if a failed
+// conversion leaves them unchanged.
+func setString(value string, segs []segment, target setTarget) func(reflect.Value) error {
+	return func(v reflect.Value) error {
+		if target.err != nil {
+			return target.err
+		}
+		if !v.CanSet() {
+			return ErrUnaddressableValue
+		}
+		var validated, inPlace = target.validated, target.inPlace
+		if v.Type() != target.typ {
+			validated, inPlace = target.check(v.Type())
+		}
+		var tmp = v
+		if !inPlace {
+			tmp = reflect.New(v.Type()).Elem()
+			tmp.Set(v)
+		}
+		var err error
+		if target.isField && isBytes(target.field.Type) {
+			err = fieldDecoder(target.field)(nil, value, tmp)
+		} else {
+			err = StringToValue(value, tmp)
+		}
+		if err != nil {
+			return err
+		}
+		if validated {
+			var errs ValidationErrors
+			if err = validate(tmp, segmentsToPath(segs), target.rules, &errs, nil); err != nil {
+				return err
+			}
+			if len(errs) > 0 {
+				return errs
+			}
+		}
+		v.Set(tmp)
+		return nil
+	}
+}
+
+// fieldKey identifies a struct field by the struct type and its name.
+type fieldKey struct {
+	t    reflect.Type
+	name string
+}
+
+// setRules caches the rules of struct fields set by setString.
+var setRules sync.Map // fieldKey -> []rule
+
+// cachedFieldRules returns the rules of field sf of struct type t, parsing
+// and caching them on first use.
+func cachedFieldRules(t reflect.Type, sf reflect.StructField) ([]rule, error) {
+	var key = fieldKey{t, sf.Name}
+	if rules, ok := setRules.Load(key); ok {
+		return rules.([]rule), nil
+	}
+	var rules, err = fieldRules(sf)
+	if err != nil {
+		return nil, err
+	}
+	setRules.Store(key, rules)
+	return rules, nil
+}
+
+// ruleTypes caches whether values of a type can contain struct fields with
+// validation rules.
+var ruleTypes sync.Map // reflect.Type -> bool
+
+// hasRules returns true if values of type t can contain struct fields with
+// validation rules or invalid validate tags. Interfaces can hold any value.
+func hasRules(t reflect.Type) bool {
+	if ok, cached := ruleTypes.Load(t); cached {
+		return ok.(bool)
+	}
+	var ok = typeHasRules(t, make(map[reflect.Type]bool))
+	ruleTypes.Store(t, ok)
+	return ok
+}
+
+// typeHasRules is the implementation of hasRules. Seen holds the types
+// being checked.
+func typeHasRules(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Ptr, reflect.Array, reflect.Slice, reflect.Map:
+		return typeHasRules(t.Elem(), seen)
+	case reflect.Struct:
+		var fields, err = structRules(t)
+		if err != nil {
+			return true
+		}
+		for _, field := range fields {
+			if len(field.rules) > 0 || typeHasRules(t.Field(field.index).Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// decodesInPlace returns true if a value of type t is left unchanged when
+// StringToValue fails to convert to it.
+func decodesInPlace(t reflect.Type) bool {
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Array, reflect.Slice, reflect.Map, reflect.Ptr, reflect.Interface:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,264 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type ValidateUpstream struct {
+	Host string `validate:"required"`
+	Port int    `validate:"min=1,max=65535"`
+}
+
+type Validated struct {
+	Name      string   `validate:"required,len=4"`
+	Port      int      `validate:"min=1,max=65535"`
+	Ratio     float64  `validate:"min=0,max=0.5"`
+	Level     string   `validate:"oneof=debug info warn"`
+	Code      string   `validate:"regexp=^[a-z]{2},[0-9]+$"`
+	Tags      []string `validate:"nonzero,max=2"`
+	Retries   *uint    `validate:"max=5"`
+	Timeout   *int     `validate:"required"`
+	Upstreams []ValidateUpstream
+	Labels    map[string]ValidateUpstream
+	NoRules   int
+}
+
+func validData() *Validated {
+	var retries uint = 3
+	var timeout = 0
+	return &Validated{
+		Name:      "main",
+		Port:      8080,
+		Ratio:     0.25,
+		Level:     "info",
+		Code:      "ab,12",
+		Tags:      []string{"a"},
+		Retries:   &retries,
+		Timeout:   &timeout,
+		Upstreams: []ValidateUpstream{{Host: "a", Port: 1}},
+		Labels:    map[string]ValidateUpstream{"x": {Host: "b", Port: 65535}},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(validData()); err != nil {
+		t.Fatal(err)
+	}
+	var data = validData()
+	var retries uint = 6
+	data.Name = "mainline"
+	data.Port = 70000
+	data.Ratio = 0.75
+	data.Level = "trace"
+	data.Code = "abc,12"
+	data.Tags = nil
+	data.Retries = &retries
+	data.Timeout = nil
+	data.Upstreams[0].Port = 0
+	data.Labels["x.y"] = ValidateUpstream{Port: 1}
+	var err = Validate(data)
+	if !errors.Is(err, ErrValidation) {
+		t.Fatal("Validate failed.")
+	}
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatal("Validate failed.")
+	}
+	var expect = []ValidationError{
+		{Path: "Name", Rule: "len=4", Value: "mainline"},
+		{Path: "Port", Rule: "max=65535", Value: "70000"},
+		{Path: "Ratio", Rule: "max=0.5", Value: "0.75"},
+		{Path: "Level", Rule: "oneof=debug info warn", Value: "trace"},
+		{Path: "Code", Rule: "regexp=^[a-z]{2},[0-9]+$", Value: "abc,12"},
+		{Path: "Tags", Rule: "nonzero", Value: ""},
+		{Path: "Retries", Rule: "max=5", Value: "6"},
+		{Path: "Timeout", Rule: "required", Value: ""},
+		{Path: "Upstreams[0].Port", Rule: "min=1", Value: "0"},
+		{Path: `Labels["x.y"].Host`, Rule: "required", Value: ""},
+	}
+	if len(errs) != len(expect) {
+		t.Fatal(err)
+	}
+	for i, e := range expect {
+		if *errs[i] != e {
+			t.Fatalf("Validate failed: %d: %+v", i, *errs[i])
+		}
+	}
+	data = validData()
+	data.Tags = []string{"a", "b", "c"}
+	if err = Validate(data); !errors.As(err, &errs) || len(errs) != 1 || errs[0].Rule != "max=2" {
+		t.Fatal("Validate failed.")
+	}
+}
+
+func TestValidateInvalidRule(t *testing.T) {
+	var tests = []interface{}{
+		&struct {
+			A int `validate:"between=1"`
+		}{},
+		&struct {
+			A int `validate:"min=x"`
+		}{},
+		&struct {
+			A int `validate:"required=1"`
+		}{},
+		&struct {
+			A string `validate:"regexp=["`
+		}{},
+		&struct {
+			A bool `validate:"min=1"`
+		}{},
+		&struct {
+			A int `validate:"len=1"`
+		}{},
+	}
+	for i, test := range tests {
+		if err := Validate(test); !errors.Is(err, ErrInvalidArgument) {
+			t.Fatalf("Validate failed: %d: %v", i, err)
+		}
+	}
+	if err := Validate(Validated{}); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal("Validate failed.")
+	}
+}
+
+func TestSetValidate(t *testing.T) {
+	var data = validData()
+	var err = Set("Port", "70000", data)
+	var verr *ValidationError
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatal("Set failed.")
+	}
+	if verr = errs[0]; verr.Path != "Port" || verr.Rule != "max=65535" || verr.Value != "70000" {
+		t.Fatal("Set failed.")
+	}
+	if data.Port != 8080 {
+		t.Fatal("Set failed.")
+	}
+	if err = Set("Port", "443", data); err != nil || data.Port != 443 {
+		t.Fatal("Set failed.")
+	}
+	if err = Set("Upstreams[0].Port", "0", data); !errors.Is(err, ErrValidation) || data.Upstreams[0].Port != 1 {
+		t.Fatal("Set failed.")
+	}
+	if err = Set("Upstreams", "{Host=a,Port=0}", data); !errors.Is(err, ErrValidation) || len(data.Upstreams) != 1 {
+		t.Fatal("Set failed.")
+	}
+	if err = Set("Retries", "9", data); !errors.Is(err, ErrValidation) || *data.Retries != 3 {
+		t.Fatal("Set failed.")
+	}
+	if err = Set("Tags[0]", "", data); err != nil {
+		t.Fatal(err)
+	}
+	if err = Set("Labels[y]", "{Port=2}", data); !errors.Is(err, ErrValidation) {
+		t.Fatal("Set failed.")
+	}
+	if _, ok := data.Labels["y"]; ok {
+		t.Fatal("Set failed.")
+	}
+	if err = SetPointer("/Level", "trace", data); !errors.Is(err, ErrValidation) || data.Level != "info" {
+		t.Fatal("SetPointer failed.")
+	}
+	if err = MustCompile("Level").Set("warn", data); err != nil || data.Level != "warn" {
+		t.Fatal("CompiledPath.Set failed.")
+	}
+	if err = MustCompile("Level").Set("trace", data); !errors.Is(err, ErrValidation) || data.Level != "warn" {
+		t.Fatal("CompiledPath.Set failed.")
+	}
+}
+
+type validateInner struct {
+	Port int `validate:"max=5"`
+}
+
+type ValidateOuter struct {
+	validateInner
+	Name string `validate:"required"`
+}
+
+type ValidateNode struct {
+	Name string `validate:"required"`
+	Next *ValidateNode
+}
+
+func TestValidateEmbedded(t *testing.T) {
+	var outer = &ValidateOuter{validateInner{9}, "a"}
+	var errs ValidationErrors
+	if err := Validate(outer); !errors.As(err, &errs) || len(errs) != 1 || errs[0].Path != "Port" {
+		t.Fatal("Validate failed.", err)
+	}
+	if err := Set("Port", "7", outer); !errors.As(err, &errs) || len(errs) != 1 || errs[0].Path != "Port" {
+		t.Fatal("Set failed.", err)
+	}
+	if err := Set("Port", "4", outer); err != nil || Validate(outer) != nil {
+		t.Fatal("Set failed.", err)
+	}
+}
+
+func TestValidateCycle(t *testing.T) {
+	var node = &ValidateNode{Name: "a"}
+	node.Next = &ValidateNode{Next: node}
+	var errs ValidationErrors
+	if err := Validate(node); !errors.As(err, &errs) || len(errs) != 1 || errs[0].Path != "Next.Name" {
+		t.Fatal("Validate failed.", err)
+	}
+	if err := Set("Next.Next.Name", "b", node); err != nil || node.Name != "b" {
+		t.Fatal("Set failed.", err)
+	}
+}
+
+func TestSetValidateCache(t *testing.T) {
+	var data = validData()
+	if err := Set("Port", "443", data); err != nil {
+		t.Fatal(err)
+	}
+	var key = fieldKey{reflect.TypeOf(Validated{}), "Port"}
+	var rules, ok = setRules.Load(key)
+	if !ok || len(rules.([]rule)) != 2 {
+		t.Fatal("SetValidateCache failed.")
+	}
+	if err := MustCompile("Port").Set("0", data); !errors.Is(err, ErrValidation) || data.Port != 443 {
+		t.Fatal("SetValidateCache failed.", err)
+	}
+	if cached, _ := setRules.Load(key); &cached.([]rule)[0] != &rules.([]rule)[0] {
+		t.Fatal("SetValidateCache failed.")
+	}
+	var tests = []struct {
+		In  interface{}
+		Out bool
+	}{
+		{0, false},
+		{PatchServer{}, false},
+		{[]ValidateUpstream{}, true},
+		{map[string]*ValidateUpstream{}, true},
+		{ValidateNode{}, true},
+		{ValidateOuter{}, true},
+		{[]interface{}{}, true},
+	}
+	for _, test := range tests {
+		if hasRules(reflect.TypeOf(test.In)) != test.Out {
+			t.Fatalf("hasRules(%T) failed.", test.In)
+		}
+	}
+	if err := Set("NoRules", "x", data); err == nil || data.NoRules != 0 {
+		t.Fatal("SetValidateCache failed.", err)
+	}
+	if err := Set("NoRules", "7", data); err != nil || data.NoRules != 7 {
+		t.Fatal("SetValidateCache failed.", err)
+	}
+}
+
+func BenchmarkSetValidated(b *testing.B) {
+	var data = validData()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Set("Upstreams[0].Port", "42", data)
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"encoding"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// WalkFunc is the type of the function called by Walk for each value visited.
+// Path is the path to value in the syntax understood by Find.
+//
+// If WalkFunc returns ErrSkipPath when invoked on a compound value Walk skips
+// its contents. If it returns any other non-nil error Walk stops and returns
+// that error.
+type WalkFunc func(path string, value reflect.Value) error
+
+// Walk walks all values contained in root, which must be a compound Go
+// value or a pointer to one, calling f for each value except root itself.
+// See WalkDepth for details.
+func Walk(root interface{}, f WalkFunc) error {
+	return WalkDepth(root, -1, f)
+}
+
+// WalkDepth walks all values contained in root up to depth levels deep,
+// calling f for each value except root itself. Each struct field, Array or
+// Slice index and Map key in a path is one level. If depth is negative
+// there is no limit.
+//
+// Exported struct fields are visited in order of declaration, Array and Slice
+// elements in order of their indexes and Map elements in sorted key order.
+// Values are visited before values they contain. Pointers and interfaces are
+// dereferenced and do not add a level. Values implementing
+// encoding.TextMarshaler are not descended into. A pointer, Slice or Map
+// that references a value containing it, i.e. a cycle, is visited but not
+// descended into.
+func WalkDepth(root interface{}, depth int, f WalkFunc) error {
+	if root == nil || f == nil {
+		return ErrInvalidArgument
+	}
+	return walk("", reflect.ValueOf(root), 0, depth, f)
+}
+
+// walk calls f for each value contained in v which is at specified level.
+func walk(path string, v reflect.Value, level, depth int, f WalkFunc) error {
+	var w = &walker{depth: depth, f: f, seen: make(map[ptrKey]bool)}
+	return w.walk(path, v, level)
+}
+
+// walker holds the state of a walk.
+type walker struct {
+	depth int
+	f     WalkFunc
+	seen  map[ptrKey]bool // values being walked
+}
+
+// walk calls visit for each value contained in v which is at specified level.
+func (w *walker) walk(path string, v reflect.Value, level int) error {
+	if w.depth >= 0 && level >= w.depth {
+		return nil
+	}
+	if key, ok := pointerKey(v); ok {
+		if w.seen[key] {
+			return nil
+		}
+		w.seen[key] = true
+		defer delete(w.seen, key)
+	}
+	return eachChild(path, v, func(path string, child reflect.Value) error {
+		return w.visit(path, child, level+1)
+	})
+}
+
+// eachChild calls f with the path and value of each exported struct field,
+// Array or Slice element or Map element contained in v, in the order defined
+// by Walk. Pointers and interfaces in v are dereferenced. Nothing is called
+// for a nil v or a v that is not a compound value.
+func eachChild(path string, v reflect.Value, f func(path string, child reflect.Value) error) error {
+	if v = indirect(v); !v.IsValid() || isLeaf(v) {
+		return nil
+	}
+	var err error
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			var field = v.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			if err = f(joinName(path, field.Name), v.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err = f(joinKey(path, strconv.Itoa(i)), v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		var key string
+		for _, mapkey := range sortedKeys(v) {
+			if key, err = valueToString(mapkey); err != nil {
+				return err
+			}
+			if err = f(joinKey(path, quoteKey(key)), v.MapIndex(mapkey)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// visit calls f for v and walks v unless f requested it to be skipped.
+func (w *walker) visit(path string, v reflect.Value, level int) error {
+	if err := w.f(path, v); err != nil {
+		if err == ErrSkipPath {
+			return nil
+		}
+		return err
+	}
+	return w.walk(path, v, level)
+}
+
+// ptrKey identifies a value referenced by a pointer, Slice or Map by its
+// address, type and, for Slices, length.
+type ptrKey struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// pointerKey returns the key of the value v references and true, or false
+// if v references nothing. Pointers and interfaces in v are dereferenced and
+// the value referenced is the non-nil Slice or Map reached, if any, or the
+// value the last non-nil pointer refers to.
+func pointerKey(v reflect.Value) (key ptrKey, ok bool) {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+		if v.Kind() == reflect.Ptr {
+			key, ok = ptrKey{v.Pointer(), v.Type(), 0}, true
+		}
+		v = v.Elem()
+	}
+	if v.IsValid() && (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && !v.IsNil() {
+		var n int
+		if v.Kind() == reflect.Slice {
+			n = v.Len()
+		}
+		key, ok = ptrKey{v.Pointer(), v.Type(), n}, true
+	}
+	return
+}
+
+// Paths returns paths of all simple values contained in root in the order
+// they are visited by Walk. Nil pointers and interfaces are omitted.
+func Paths(root interface{}) ([]string, error) {
+	var paths []string
+	if err := Walk(root, func(path string, value reflect.Value) error {
+		if value = indirect(value); value.IsValid() && isLeaf(value) {
+			paths = append(paths, path)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// isLeaf returns true if v is not a compound value or is a value that
+// converts to and from text itself.
+func isLeaf(v reflect.Value) bool {
+	if v.Type().Implements(textMarshalerType) ||
+		reflect.PtrTo(v.Type()).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Struct, reflect.Array, reflect.Slice, reflect.Map, reflect.Ptr, reflect.Interface:
+		return false
+	}
+	return true
+}
+
+// joinName appends a struct field name element to path.
+func joinName(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// joinKey appends a key element to path.
+func joinKey(path, key string) string {
+	return path + "[" + key + "]"
+}
+
+// quoteKey quotes key if it is empty, contains characters that have special
+// meaning in a path or would be interpreted as a wildcard or a range.
+func quoteKey(key string) string {
+	if key == "" || strings.ContainsAny(key, `."[]`) || KeyTokenOf(key) != KeyToken ||
+		strings.IndexFunc(key, func(r rune) bool { return !strconv.IsPrint(r) }) >= 0 {
+		return strconv.Quote(key)
+	}
+	return key
+}
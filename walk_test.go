@@ -0,0 +1,179 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package strconvex
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type WalkChild struct {
+	Name string
+	Tags []string
+}
+
+type WalkRoot struct {
+	Port    int
+	Time    time.Time
+	Child   *WalkChild
+	Nil     *WalkChild
+	Map     map[int]WalkChild
+	Labels  map[string]string
+	private int
+}
+
+func getWalkData() *WalkRoot {
+	return &WalkRoot{
+		Port:  80,
+		Child: &WalkChild{Name: "child", Tags: []string{"a", "b"}},
+		Map: map[int]WalkChild{
+			10: {Name: "ten"},
+			2:  {Name: "two"},
+		},
+		Labels: map[string]string{"b": "2", "a.b": "1"},
+	}
+}
+
+func TestWalk(t *testing.T) {
+	var paths []string
+	if err := Walk(getWalkData(), func(path string, value reflect.Value) error {
+		paths = append(paths, path)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var expect = []string{
+		"Port",
+		"Time",
+		"Child",
+		"Child.Name",
+		"Child.Tags",
+		"Child.Tags[0]",
+		"Child.Tags[1]",
+		"Nil",
+		"Map",
+		"Map[2]",
+		"Map[2].Name",
+		"Map[2].Tags",
+		"Map[10]",
+		"Map[10].Name",
+		"Map[10].Tags",
+		"Labels",
+		`Labels["a.b"]`,
+		"Labels[b]",
+	}
+	if !reflect.DeepEqual(paths, expect) {
+		t.Fatalf("Walk failed: want %v, got %v", expect, paths)
+	}
+}
+
+func TestWalkSkipPath(t *testing.T) {
+	var paths []string
+	if err := Walk(getWalkData(), func(path string, value reflect.Value) error {
+		paths = append(paths, path)
+		if path != "Child" {
+			return ErrSkipPath
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var expect = []string{"Port", "Time", "Child", "Child.Name", "Child.Tags", "Nil", "Map", "Labels"}
+	if !reflect.DeepEqual(paths, expect) {
+		t.Fatalf("Walk failed: want %v, got %v", expect, paths)
+	}
+}
+
+func TestWalkDepth(t *testing.T) {
+	var paths []string
+	if err := WalkDepth(getWalkData(), 2, func(path string, value reflect.Value) error {
+		paths = append(paths, path)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var expect = []string{
+		"Port", "Time", "Child", "Child.Name", "Child.Tags", "Nil",
+		"Map", "Map[2]", "Map[10]", "Labels", `Labels["a.b"]`, "Labels[b]",
+	}
+	if !reflect.DeepEqual(paths, expect) {
+		t.Fatalf("WalkDepth failed: want %v, got %v", expect, paths)
+	}
+}
+
+func TestPaths(t *testing.T) {
+	var data = getWalkData()
+	data.Labels[`a]"b`] = "3"
+	data.Labels[""] = "4"
+	var paths, err = Paths(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expect = []string{
+		"Port",
+		"Time",
+		"Child.Name",
+		"Child.Tags[0]",
+		"Child.Tags[1]",
+		"Map[2].Name",
+		"Map[10].Name",
+		`Labels[""]`,
+		`Labels["a.b"]`,
+		`Labels["a]\"b"]`,
+		"Labels[b]",
+	}
+	if !reflect.DeepEqual(paths, expect) {
+		t.Fatalf("Paths failed: want %v, got %v", expect, paths)
+	}
+	for _, path := range paths {
+		var v reflect.Value
+		if v, err = Find(path, data); err != nil {
+			t.Fatal(path, err)
+		}
+		if s, _ := ValueToString(v); path == `Labels["a]\"b"]` && s != "3" {
+			t.Fatal("Paths failed.")
+		}
+	}
+}
+
+type WalkNode struct {
+	Name string
+	Next *WalkNode
+}
+
+func TestWalkCycle(t *testing.T) {
+	var node = &WalkNode{Name: "a"}
+	node.Next = &WalkNode{Name: "b", Next: node}
+	var paths []string
+	if err := Walk(node, func(path string, value reflect.Value) error {
+		paths = append(paths, path)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var expect = []string{"Name", "Next", "Next.Name", "Next.Next"}
+	if !reflect.DeepEqual(paths, expect) {
+		t.Fatalf("Walk failed: want %v, got %v", expect, paths)
+	}
+	var shared = &WalkChild{Name: "shared"}
+	var dag = []*WalkChild{shared, shared}
+	if paths, _ = Paths(dag); len(paths) != 2 {
+		t.Fatal("Paths failed.")
+	}
+	var list = []interface{}{1, nil}
+	list[1] = list
+	if paths, _ = Paths(list); !reflect.DeepEqual(paths, []string{"[0]"}) {
+		t.Fatalf("Paths failed: %v", paths)
+	}
+	var m = map[string]interface{}{"a": 1}
+	m["self"] = m
+	if paths, _ = Paths(m); !reflect.DeepEqual(paths, []string{"[a]"}) {
+		t.Fatalf("Paths failed: %v", paths)
+	}
+	if _, err := Flatten(m); err != nil {
+		t.Fatal(err)
+	}
+}